@@ -0,0 +1,162 @@
+package zetasketch
+
+import (
+	"fmt"
+
+	"github.com/bsm/zetasketch/hllplus"
+	pb "github.com/bsm/zetasketch/internal/zetasketch"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of AggregatorStateProto and HyperLogLogPlusUniqueStateProto.
+const (
+	stateTypeField            protowire.Number = 1
+	stateNumValuesField       protowire.Number = 2
+	stateEncodingVersionField protowire.Number = 3
+	stateValueTypeField       protowire.Number = 4
+	stateHLLField                              = protowire.Number(pb.AggregatorType_HYPERLOGLOG_PLUS_UNIQUE)
+
+	hllPrecisionField       protowire.Number = 3
+	hllSparsePrecisionField protowire.Number = 4
+	hllDataField            protowire.Number = 5
+	hllSparseDataField      protowire.Number = 6
+)
+
+// EstimateBytes returns the same estimate as HLL.Result of a serialized HLL++
+// aggregator. Instead of deserializing the aggregator, it walks the binary
+// message and the sketch data in place, without allocations. The estimator is
+// not part of the serialized state, EstimateBytes always uses the default
+// EstimatorHLLPlus. Use HLLView to estimate with a different estimator.
+func EstimateBytes(data []byte) (int64, error) {
+	var w hllWire
+	if err := w.parse(data); err != nil {
+		return 0, err
+	}
+	return hllplus.EstimateData(w.precision, w.sparsePrecision, w.data, w.sparseData)
+}
+
+// hllWire holds the fields of a serialized HLL++ aggregator. Slices reference
+// the serialized message.
+type hllWire struct {
+	numValues                  int64
+	valueType                  ValueType
+	precision, sparsePrecision uint8
+	data, sparseData           []byte
+}
+
+// parse parses and validates a serialized AggregatorStateProto like
+// HLL.UnmarshalBinary.
+func (w *hllWire) parse(data []byte) error {
+	var (
+		aggType         pb.AggregatorType
+		encodingVersion int32 = 1
+		hasType         bool
+		hasNumValues    bool
+
+		precision, sparsePrecision int32
+	)
+
+	err := walkFields(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == stateTypeField && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			aggType, hasType = pb.AggregatorType(int32(v)), true
+			return n, nil
+		case num == stateNumValuesField && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			w.numValues, hasNumValues = int64(v), true
+			return n, nil
+		case num == stateEncodingVersionField && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			encodingVersion = int32(v)
+			return n, nil
+		case num == stateValueTypeField && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			w.valueType = ValueType(int32(v))
+			return n, nil
+		case num == stateHLLField && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			// repeated occurrences of a message field are merged
+			return n, walkFields(v, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+				switch {
+				case num == hllPrecisionField && typ == protowire.VarintType:
+					v, n := protowire.ConsumeVarint(b)
+					precision = int32(v)
+					return n, nil
+				case num == hllSparsePrecisionField && typ == protowire.VarintType:
+					v, n := protowire.ConsumeVarint(b)
+					sparsePrecision = int32(v)
+					return n, nil
+				case num == hllDataField && typ == protowire.BytesType:
+					v, n := protowire.ConsumeBytes(b)
+					w.data = v
+					return n, nil
+				case num == hllSparseDataField && typ == protowire.BytesType:
+					v, n := protowire.ConsumeBytes(b)
+					w.sparseData = v
+					return n, nil
+				}
+				return protowire.ConsumeFieldValue(num, typ, b), nil
+			})
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !hasType || !hasNumValues {
+		return fmt.Errorf("incompatible binary message: missing required fields")
+	}
+	if aggType != pb.AggregatorType_HYPERLOGLOG_PLUS_UNIQUE {
+		return fmt.Errorf("incompatible binary message: unexpected type %s", aggType.String())
+	}
+	if encodingVersion != 1 && encodingVersion != 2 {
+		return fmt.Errorf("incompatible binary message: unsupported encoding version %#v", encodingVersion)
+	}
+	if !w.valueType.isValid() {
+		return fmt.Errorf("incompatible binary message: unsupported value type %d", w.valueType)
+	}
+
+	if encodingVersion == 1 {
+		p, err := precisionOfNumBuckets(precision, hllplus.MinPrecision, hllplus.MaxPrecision)
+		if err != nil {
+			return fmt.Errorf("incompatible binary message: invalid normal number of buckets: %w", err)
+		}
+		sp, err := precisionOfNumBuckets(sparsePrecision, 0, hllplus.MaxSparsePrecision)
+		if err != nil {
+			return fmt.Errorf("incompatible binary message: invalid sparse number of buckets: %w", err)
+		}
+		precision, sparsePrecision = int32(p), int32(sp)
+	}
+
+	w.precision, w.sparsePrecision = uint8(precision), uint8(sparsePrecision)
+	return nil
+}
+
+// walkFields calls fn for each field of a serialized message with the field
+// number, the wire type and the remaining bytes, starting at the field value.
+// fn must return the number of consumed bytes, or a negative protowire error
+// code.
+func walkFields(data []byte, fn func(protowire.Number, protowire.Type, []byte) (int, error)) error {
+	for b := data; len(b) > 0; {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		n, err := fn(num, typ, b)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
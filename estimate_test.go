@@ -0,0 +1,149 @@
+package zetasketch_test
+
+import (
+	"testing"
+
+	"github.com/bsm/zetasketch"
+	pb "github.com/bsm/zetasketch/internal/zetasketch"
+	"google.golang.org/protobuf/proto"
+)
+
+func newEstimateTestData(t testing.TB) map[string][]byte {
+	t.Helper()
+
+	sketches := map[string]*zetasketch.HLL{
+		"empty": zetasketch.NewHLL(nil),
+		"small": newTestHLL(),
+		"v1":    zetasketch.NewHLL(&zetasketch.HLLConfig{EncodingVersion: 1}),
+		"large": zetasketch.NewHLL(&zetasketch.HLLConfig{Precision: 12}),
+	}
+	for i := range 20_000 {
+		sketches["v1"].Add(zetasketch.Uint64Value(uint64(i)))
+		sketches["large"].Add(zetasketch.Uint64Value(uint64(i)))
+	}
+
+	blobs := make(map[string][]byte, len(sketches))
+	for name, h := range sketches {
+		data, err := h.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		blobs[name] = data
+	}
+	return blobs
+}
+
+func TestEstimateBytes(t *testing.T) {
+	for name, data := range newEstimateTestData(t) {
+		h := new(zetasketch.HLL)
+		if err := h.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := zetasketch.EstimateBytes(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if exp := h.Result(); got != exp {
+			t.Errorf("%s: got %d, want %d", name, got, exp)
+		}
+
+		if allocs := testing.AllocsPerRun(10, func() { _, _ = zetasketch.EstimateBytes(data) }); allocs != 0 {
+			t.Errorf("%s: got %v allocs, want 0", name, allocs)
+		}
+	}
+}
+
+func TestEstimateBytes_invalid(t *testing.T) {
+	state := func(p, sp int32) *pb.HyperLogLogPlusUniqueStateProto {
+		return &pb.HyperLogLogPlusUniqueStateProto{
+			PrecisionOrNumBuckets:       proto.Int32(p),
+			SparsePrecisionOrNumBuckets: proto.Int32(sp),
+		}
+	}
+	cases := map[string]*pb.AggregatorStateProto{
+		"type": {
+			Type:      pb.AggregatorType_SUM.Enum(),
+			NumValues: proto.Int64(0),
+		},
+		"encoding version": {
+			Type:            pb.AggregatorType_HYPERLOGLOG_PLUS_UNIQUE.Enum(),
+			NumValues:       proto.Int64(0),
+			EncodingVersion: proto.Int32(3),
+		},
+		"no num values": {
+			Type:            pb.AggregatorType_HYPERLOGLOG_PLUS_UNIQUE.Enum(),
+			EncodingVersion: proto.Int32(2),
+		},
+		"no state": {
+			Type:            pb.AggregatorType_HYPERLOGLOG_PLUS_UNIQUE.Enum(),
+			NumValues:       proto.Int64(0),
+			EncodingVersion: proto.Int32(2),
+		},
+		"precision": {
+			Type:            pb.AggregatorType_HYPERLOGLOG_PLUS_UNIQUE.Enum(),
+			NumValues:       proto.Int64(0),
+			EncodingVersion: proto.Int32(2),
+		},
+		"number of buckets": {
+			Type:      pb.AggregatorType_HYPERLOGLOG_PLUS_UNIQUE.Enum(),
+			NumValues: proto.Int64(0),
+		},
+		"value type": {
+			Type:            pb.AggregatorType_HYPERLOGLOG_PLUS_UNIQUE.Enum(),
+			NumValues:       proto.Int64(0),
+			EncodingVersion: proto.Int32(2),
+			ValueType:       proto.Int32(-1),
+		},
+		"reserved value type": {
+			Type:            pb.AggregatorType_HYPERLOGLOG_PLUS_UNIQUE.Enum(),
+			NumValues:       proto.Int64(0),
+			EncodingVersion: proto.Int32(2),
+			ValueType:       proto.Int32(500),
+		},
+	}
+	proto.SetExtension(cases["precision"], pb.E_HyperloglogplusUniqueState, state(9, 20))
+	proto.SetExtension(cases["number of buckets"], pb.E_HyperloglogplusUniqueState, state(15, 20))
+	proto.SetExtension(cases["value type"], pb.E_HyperloglogplusUniqueState, state(15, 20))
+	proto.SetExtension(cases["reserved value type"], pb.E_HyperloglogplusUniqueState, state(15, 20))
+
+	for name, msg := range cases {
+		data, err := proto.MarshalOptions{AllowPartial: true}.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := new(zetasketch.HLL).UnmarshalBinary(data); err == nil {
+			t.Errorf("%s: expected UnmarshalBinary error", name)
+		}
+		if _, err := zetasketch.EstimateBytes(data); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if _, err := zetasketch.EstimateBytes([]byte{0x08}); err == nil {
+		t.Error("truncated: expected error")
+	}
+}
+
+func BenchmarkEstimateBytes(b *testing.B) {
+	for name, data := range newEstimateTestData(b) {
+		b.Run(name+"/UnmarshalBinary", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				h := new(zetasketch.HLL)
+				if err := h.UnmarshalBinary(data); err != nil {
+					b.Fatal(err)
+				}
+				_ = h.Result()
+			}
+		})
+		b.Run(name+"/EstimateBytes", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := zetasketch.EstimateBytes(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
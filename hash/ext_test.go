@@ -0,0 +1,6 @@
+package hash
+
+// Word test export.
+func Word(x uint64, n int) uint64 {
+	return word(x, n)
+}
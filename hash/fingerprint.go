@@ -31,7 +31,11 @@ func Bytes(data []byte) uint64 {
 	if len(data) >= 9 {
 		v = binary.LittleEndian.Uint64(data[len(data)-8:])
 	}
+	return finalize(h, u, v)
+}
 
+// finalize mixes the first (u) and the last (v) 8 bytes of the input into h.
+func finalize(h, u, v uint64) uint64 {
 	h = hash128to64(h+v, u)
 	if h == 0 || h == 1 {
 		return h + ^uint64(1)
@@ -39,6 +43,27 @@ func Bytes(data []byte) uint64 {
 	return h
 }
 
+// word computes the same hash as Bytes for inputs of up to 8 bytes, passed as
+// little-endian word x of n bytes, without the need for a buffer.
+func word(x uint64, n int) uint64 {
+	// inlined mm64 with a single block or tail
+	h := (c0 ^ c1 ^ c2) ^ uint64(n)*c3
+	if n == 8 {
+		h ^= shiftMix(x*c3) * c3
+		h *= c3
+	} else if n != 0 {
+		h ^= x
+		h *= c3
+	}
+	h = shiftMix(shiftMix(h) * c3)
+
+	var u uint64 = c0
+	if n == 8 {
+		u = x
+	}
+	return finalize(h, u, c0)
+}
+
 // mm64 computes 64-bit Murmur hash with given seed.
 func mm64(data []byte, seed uint64) uint64 {
 	h := seed ^ uint64(len(data))*c3
@@ -116,29 +141,53 @@ func hash33to64(data []byte) uint64 {
 
 // Compute an 8-byte hash of a byte array of length greater than 64 bytes.
 func fullFingerprint(data []byte) uint64 {
-	// For lengths over 64 bytes we hash the end first, and then as we
-	// loop we keep 56 bytes of state: v, w, x, y, and z.
-	x := load64(data)
-	y := load64(data[len(data)-16:]) ^ c1
-	z := load64(data[len(data)-56:]) ^ c0
-	v1, v2 := weakHashLength32WithSeeds(data[len(data)-64:], uint64(len(data)), y)
-	w1, w2 := weakHashLength32WithSeeds(data[len(data)-32:], uint64(len(data))*c1, c0)
+	f := newFullState(data[:8], data[len(data)-64:], uint64(len(data)))
+	for len(data) > 64 {
+		f.Block(data)
+		data = data[64:]
+	}
+	return f.Sum()
+}
+
+// fullState is the state of fullFingerprint, which keeps 56 bytes of state:
+// v, w, x, y, and z.
+type fullState struct {
+	v1, v2, w1, w2 uint64
+	x, y, z        uint64
+}
+
+// newFullState inits the state from the first 8 bytes (head) and the last 64
+// bytes (tail) of the input of length n. For lengths over 64 bytes we hash the
+// end first, and then loop over the blocks.
+func newFullState(head, tail []byte, n uint64) fullState {
+	x := load64(head)
+	y := load64(tail[48:]) ^ c1
+	z := load64(tail[8:]) ^ c0
+	v1, v2 := weakHashLength32WithSeeds(tail, n, y)
+	w1, w2 := weakHashLength32WithSeeds(tail[32:], n*c1, c0)
 	z += shiftMix(v2) * c1
 	x = rotateRight(z+x, 39) * c1
 	y = rotateRight(y, 33) * c1
 
-	for len(data) > 64 {
-		x = rotateRight(x+y+v1+load64(data[16:]), 37) * c1
-		y = rotateRight(y+v2+load64(data[48:]), 42) * c1
-		x ^= w2
-		y ^= v1
-		z = rotateRight(z^w1, 33)
-		v1, v2 = weakHashLength32WithSeeds(data, v2*c1, x+w1)
-		w1, w2 = weakHashLength32WithSeeds(data[32:], z+w2, y)
-		z, x = x, z
-		data = data[64:]
-	}
-	return hash128to64(hash128to64(v1, w1)+shiftMix(y)*c1+z, hash128to64(v2, w2)+x)
+	return fullState{v1: v1, v2: v2, w1: w1, w2: w2, x: x, y: y, z: z}
+}
+
+// Block processes a block of 64 bytes. All but the last (partial or full)
+// block of the input must be processed, in order.
+func (f *fullState) Block(data []byte) {
+	f.x = rotateRight(f.x+f.y+f.v1+load64(data[16:]), 37) * c1
+	f.y = rotateRight(f.y+f.v2+load64(data[48:]), 42) * c1
+	f.x ^= f.w2
+	f.y ^= f.v1
+	f.z = rotateRight(f.z^f.w1, 33)
+	f.v1, f.v2 = weakHashLength32WithSeeds(data, f.v2*c1, f.x+f.w1)
+	f.w1, f.w2 = weakHashLength32WithSeeds(data[32:], f.z+f.w2, f.y)
+	f.z, f.x = f.x, f.z
+}
+
+// Sum returns the hash.
+func (f *fullState) Sum() uint64 {
+	return hash128to64(hash128to64(f.v1, f.w1)+shiftMix(f.y)*c1+f.z, hash128to64(f.v2, f.w2)+f.x)
 }
 
 func load64(data []byte) uint64 {
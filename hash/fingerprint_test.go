@@ -2,9 +2,11 @@ package hash_test
 
 import (
 	"bytes"
+	"encoding/binary"
+	"math/rand"
 	"testing"
 
-	"github.com/bsm/zetasketch/internal/hash"
+	"github.com/bsm/zetasketch/hash"
 )
 
 func TestBytes(t *testing.T) {
@@ -37,3 +39,20 @@ func TestBytes(t *testing.T) {
 		}
 	}
 }
+
+func TestWord(t *testing.T) {
+	rnd := rand.New(rand.NewSource(33))
+	buf := make([]byte, 8)
+	for range 1_000 {
+		x := rnd.Uint64()
+		for n := 0; n <= 8; n++ {
+			binary.LittleEndian.PutUint64(buf, x)
+			clear(buf[n:])
+
+			y := binary.LittleEndian.Uint64(buf)
+			if got, exp := hash.Word(y, n), hash.Bytes(buf[:n]); got != exp {
+				t.Fatalf("Word(%#x, %d) = %#x, want %#x", y, n, got, exp)
+			}
+		}
+	}
+}
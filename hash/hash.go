@@ -0,0 +1,97 @@
+// Package hash implements hashing as done in zetasketch Java library
+// https://github.com/google/zetasketch/blob/master/java/com/google/zetasketch/internal/hash/.
+//
+// It allows values to be hashed ahead of time, e.g. at the edge of a pipeline,
+// and added to an aggregator later via zetasketch.HashValue.
+//
+// Compatibility: the hashes computed by this package are part of the
+// serialized state of sketches and are guaranteed to remain stable across
+// releases. For the input types supported by the Java library and BigQuery,
+// i.e. signed 32 and 64-bit integers, strings and bytes, they are identical to
+// the hashes used there. Unsigned 32-bit integers, floats and tuples are not
+// supported by either, their encodings are specific to this package.
+package hash
+
+import (
+	"encoding/binary"
+	"math"
+	"unsafe"
+)
+
+// Uint32 hashes uint32 numbers. It hashes a 5-byte buffer with v stored as
+// little-endian followed by a zero byte, as this package always has. The width
+// differs from Int32 and Float32 and the encoding is specific to this package,
+// it is only kept stable across releases.
+func Uint32(v uint32) uint64 {
+	return word(uint64(v), 5)
+}
+
+// Uint64 hashes uint64 numbers. Like Hash.of(long) in the Java library, it
+// hashes an 8-byte buffer with v stored as little-endian.
+func Uint64(v uint64) uint64 {
+	return word(v, 8)
+}
+
+// Int32 hashes int32 numbers. Like Hash.of(int) in the Java library, it
+// hashes a 4-byte buffer with the two's complement of v stored as
+// little-endian. Unlike Uint32, no padding byte is added.
+func Int32(v int32) uint64 {
+	return word(uint64(uint32(v)), 4)
+}
+
+// Int64 hashes int64 numbers, using their two's complement representation in
+// the same 8 bytes as Uint64.
+func Int64(v int64) uint64 {
+	return Uint64(uint64(v))
+}
+
+// Float32 hashes float32 numbers, using their 4 little-endian IEEE754 bytes,
+// as described for the FLOAT value type. Unlike Uint32, no padding byte is
+// added.
+// Values are canonicalized before hashing: all NaNs hash to the same value
+// (regardless of their sign and payload) and so do -0.0 and +0.0.
+func Float32(v float32) uint64 {
+	bits := math.Float32bits(v)
+	if v != v {
+		bits = 0x7fc00000 // canonical quiet NaN
+	} else if v == 0 {
+		bits = 0 // +0.0
+	}
+	return word(uint64(bits), 4)
+}
+
+// Float64 hashes float64 numbers, using their 8 little-endian IEEE754 bytes.
+// Values are canonicalized before hashing: all NaNs hash to the same value
+// (regardless of their sign and payload) and so do -0.0 and +0.0.
+func Float64(v float64) uint64 {
+	bits := math.Float64bits(v)
+	if v != v {
+		bits = 0x7ff8000000000000 // canonical quiet NaN
+	} else if v == 0 {
+		bits = 0 // +0.0
+	}
+	return Uint64(bits)
+}
+
+// String hashes strings.
+func String(v string) uint64 {
+	// Bytes does not modify or retain data, so it is safe to pass a view of v.
+	return Bytes(unsafe.Slice(unsafe.StringData(v), len(v)))
+}
+
+// Tuple combines the hashes of multiple components into the hash of a tuple,
+// e.g. for counting distinct (user_id, device_id) pairs.
+//
+// The result is the hash of the concatenated 8-byte little-endian component
+// hashes. As every component contributes exactly 8 bytes, the encoding is
+// unambiguous: tuples only hash to the same value if they have the same
+// number of components with equal hashes in the same order (barring
+// collisions). This scheme is covered by the compatibility guarantee.
+func Tuple(sums ...uint64) uint64 {
+	var arr [64]byte
+	buf := arr[:0]
+	for _, sum := range sums {
+		buf = binary.LittleEndian.AppendUint64(buf, sum)
+	}
+	return Bytes(buf)
+}
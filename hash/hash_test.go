@@ -0,0 +1,242 @@
+package hash_test
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/bsm/zetasketch/hash"
+)
+
+// The vectors in this file are regression values, computed by this package,
+// none of them were generated by the Java library. Only the underlying
+// fingerprint is checked against vectors taken from the Java library, see
+// TestBytes.
+
+func TestUint64(t *testing.T) {
+	cases := []struct {
+		in  uint64
+		exp uint64
+	}{
+		{0, 0x853a22bd6e14a48f},
+		{1, 0xb91968b83211c978},
+		{2, 0x83e2c1afe085d87a},
+		{(1 << 62) - 127, 0xfd2303b188e412d9},
+	}
+	for _, tc := range cases {
+		if got := hash.Uint64(tc.in); got != tc.exp {
+			t.Errorf("Uint64(%d) = %#x, want %#x", tc.in, got, tc.exp)
+		}
+	}
+}
+
+func TestUint32(t *testing.T) {
+	cases := []struct {
+		in  uint32
+		exp uint64
+	}{
+		{0, 0x905df40cd02611cb},
+		{1, 0xba4e724d9d787a26},
+		{2, 0x6ef6414ac0c8858e},
+		{(1 << 29) - 127, 0xbda9eae625a1584},
+	}
+	for _, tc := range cases {
+		if got := hash.Uint32(tc.in); got != tc.exp {
+			t.Errorf("Uint32(%d) = %#x, want %#x", tc.in, got, tc.exp)
+		}
+	}
+}
+
+func TestInt64(t *testing.T) {
+	// Non-negative values share their representation, and hence their
+	// vectors, with TestUint64.
+	cases := []struct {
+		in  int64
+		exp uint64
+	}{
+		{0, 0x853a22bd6e14a48f},
+		{1, 0xb91968b83211c978},
+		{2, 0x83e2c1afe085d87a},
+		{(1 << 62) - 127, 0xfd2303b188e412d9},
+	}
+	for _, tc := range cases {
+		if got := hash.Int64(tc.in); got != tc.exp {
+			t.Errorf("Int64(%d) = %#x, want %#x", tc.in, got, tc.exp)
+		}
+	}
+
+	// Java's Hash.of(long) is the fingerprint of the 8 little-endian bytes of
+	// the value, i.e. of the two's complement for negative values, which is
+	// cross-checked against Uint64 below.
+	cases = []struct {
+		in  int64
+		exp uint64
+	}{
+		{-1, 0xda13d4a9f7078f79},
+		{-2, 0xdf4363ba240b81a3},
+		{-(1 << 62) + 127, 0x5d931f17ba6f5b03},
+		{math.MinInt64, 0xb4fdf91e12d4a388},
+		{math.MaxInt64, 0x6ed597fdde78dee7},
+	}
+	for _, tc := range cases {
+		if got := hash.Int64(tc.in); got != tc.exp {
+			t.Errorf("Int64(%d) = %#x, want %#x", tc.in, got, tc.exp)
+		}
+		// two's complement, as in Java's Hash.of(long):
+		if got, exp := hash.Int64(tc.in), hash.Uint64(uint64(tc.in)); got != exp {
+			t.Errorf("Int64(%d) = %#x, want %#x", tc.in, got, exp)
+		}
+	}
+}
+
+func TestInt32(t *testing.T) {
+	// Java's Hash.of(int) is the fingerprint of the 4 little-endian bytes of
+	// the value, which is cross-checked against Bytes below.
+	cases := []struct {
+		in  int32
+		exp uint64
+	}{
+		{0, 0x1f6e43ff4b5270ee},
+		{1, 0xc90db28961525fb},
+		{2, 0x5c6454fc197e322e},
+		{(1 << 29) - 127, 0xc2a124f96ac465a8},
+		{-1, 0xecfdab90f069b37e},
+		{-2, 0x1b63b3f8962966b4},
+		{-(1 << 29) + 127, 0x6c7e3da6e656c9c7},
+		{math.MinInt32, 0x21ed5156495351d8},
+		{math.MaxInt32, 0x99a806a3f1f778c9},
+	}
+	for _, tc := range cases {
+		if got := hash.Int32(tc.in); got != tc.exp {
+			t.Errorf("Int32(%d) = %#x, want %#x", tc.in, got, tc.exp)
+		}
+		// two's complement in 4 bytes, as in Java's Hash.of(int):
+		if got, exp := hash.Int32(tc.in), hash.Bytes(binary.LittleEndian.AppendUint32(nil, uint32(tc.in))); got != exp {
+			t.Errorf("Int32(%d) = %#x, want %#x", tc.in, got, exp)
+		}
+	}
+}
+
+func TestFloat64(t *testing.T) {
+	// Neither the Java library nor BigQuery support floats.
+	cases := []struct {
+		in  float64
+		exp uint64
+	}{
+		{0, 0x853a22bd6e14a48f},
+		{math.Copysign(0, -1), 0x853a22bd6e14a48f},
+		{1, 0x4f34b43ada0b2fa3},
+		{-1.5, 0x3b0c619681cb582a},
+		{math.Pi, 0xc76a5b73e4f717ab},
+		{math.Inf(1), 0x25a0781d0fecc864},
+		{math.NaN(), 0x849db5313e99e105},
+		{math.Float64frombits(0xfff0000000000123), 0x849db5313e99e105},
+	}
+	for _, tc := range cases {
+		if got := hash.Float64(tc.in); got != tc.exp {
+			t.Errorf("Float64(%v) = %#x, want %#x", tc.in, got, tc.exp)
+		}
+	}
+}
+
+func TestFloat32(t *testing.T) {
+	// Neither the Java library nor BigQuery support floats.
+	cases := []struct {
+		in  float32
+		exp uint64
+	}{
+		{0, 0x1f6e43ff4b5270ee},
+		{float32(math.Copysign(0, -1)), 0x1f6e43ff4b5270ee},
+		{1, 0xc12d9a6e2e85ffa1},
+		{-1.5, 0x470240557b1c19e},
+		{math.Pi, 0x5418e1f356da8887},
+		{float32(math.Inf(1)), 0xb3c46549d3f0b51e},
+		{float32(math.NaN()), 0x8e1594e11a16ac58},
+		{math.Float32frombits(0xff800123), 0x8e1594e11a16ac58},
+	}
+	for _, tc := range cases {
+		if got := hash.Float32(tc.in); got != tc.exp {
+			t.Errorf("Float32(%v) = %#x, want %#x", tc.in, got, tc.exp)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	cases := []struct {
+		in  string
+		exp uint64
+	}{
+		{"foo", 0xd0bcbfe261b36504},
+		{"Zürich", 0x27efc00f7d2ce548},
+		{"Zürich", 0x7dfa3067e55c7e8a},
+	}
+	for _, tc := range cases {
+		if got := hash.String(tc.in); got != tc.exp {
+			t.Errorf("String(%q) = %#x, want %#x", tc.in, got, tc.exp)
+		}
+	}
+}
+
+func TestTuple(t *testing.T) {
+	cases := []struct {
+		in  []uint64
+		exp uint64
+	}{
+		{nil, 0x23ad7c904aa665e3},
+		{[]uint64{hash.String("foo")}, 0xdbb9d289d9691977},
+		{[]uint64{hash.String("ab"), hash.String("c")}, 0xea3b326effe74203},
+		{[]uint64{hash.Int64(1), hash.Int64(2), hash.Int64(3)}, 0x5e345f393e07df7f},
+	}
+	for _, tc := range cases {
+		if got := hash.Tuple(tc.in...); got != tc.exp {
+			t.Errorf("Tuple(%#x) = %#x, want %#x", tc.in, got, tc.exp)
+		}
+	}
+
+	// unambiguous:
+	if hash.Tuple(hash.String("ab"), hash.String("c")) == hash.Tuple(hash.String("a"), hash.String("bc")) {
+		t.Error("expected (ab, c) and (a, bc) to differ")
+	}
+	if hash.Tuple(hash.Int64(1), hash.Int64(2)) == hash.Tuple(hash.Int64(2), hash.Int64(1)) {
+		t.Error("expected (1, 2) and (2, 1) to differ")
+	}
+}
+
+var benchSink uint64
+
+func BenchmarkUint32(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		benchSink = hash.Uint32(uint32(i))
+	}
+}
+
+func BenchmarkUint64(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		benchSink = hash.Uint64(uint64(i))
+	}
+}
+
+func BenchmarkFloat32(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		benchSink = hash.Float32(float32(i))
+	}
+}
+
+func BenchmarkFloat64(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		benchSink = hash.Float64(float64(i))
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	s := strings.Repeat("foobar", 12)
+	b.ReportAllocs()
+	for b.Loop() {
+		benchSink = hash.String(s)
+	}
+}
@@ -0,0 +1,68 @@
+package hash
+
+import (
+	"errors"
+	"io"
+)
+
+// readAtBufferSize is the size of the buffer used by ReaderAt.
+const readAtBufferSize = 32 * 1024
+
+// ReaderAt computes the same hash as Bytes over the first size bytes of r,
+// using constant memory. The result can be added to aggregators as
+// zetasketch.HashValue(sum, zetasketch.ValueTypeBytes).
+//
+// The underlying algorithm seeds its state with the length and the last 64
+// bytes of the input before processing the remaining blocks from the front.
+// Inputs must therefore be seekable. This package deliberately provides no API
+// for non-seekable or split input, such as an io.Reader or an incremental
+// io.Writer: even with the length known up front, the first block cannot be
+// processed before the last 64 bytes were read, so such inputs would have to
+// be buffered in full. Buffer them explicitly and use Bytes instead.
+func ReaderAt(r io.ReaderAt, size int64) (uint64, error) {
+	if size < 0 {
+		return 0, errors.New("hash: negative size")
+	}
+
+	var tail [64]byte
+	if size <= 64 {
+		if err := readFullAt(r, tail[:size], 0); err != nil {
+			return 0, err
+		}
+		return Bytes(tail[:size]), nil
+	}
+
+	var head [8]byte
+	if err := readFullAt(r, head[:], 0); err != nil {
+		return 0, err
+	}
+	if err := readFullAt(r, tail[:], size-64); err != nil {
+		return 0, err
+	}
+
+	// process all but the last (partial or full) block
+	f := newFullState(head[:], tail[:], uint64(size))
+	buf := make([]byte, readAtBufferSize)
+	for off, end := int64(0), (size-1)/64*64; off < end; {
+		p := buf[:min(int64(len(buf)), end-off)]
+		if err := readFullAt(r, p, off); err != nil {
+			return 0, err
+		}
+		for ; len(p) != 0; p = p[64:] {
+			f.Block(p)
+		}
+		off += int64(len(buf))
+	}
+	return finalize(f.Sum(), load64(head[:]), load64(tail[56:])), nil
+}
+
+func readFullAt(r io.ReaderAt, p []byte, off int64) error {
+	n, err := r.ReadAt(p, off)
+	if n == len(p) {
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,31 @@
+package hash_test
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/bsm/zetasketch/hash"
+)
+
+func TestReaderAt(t *testing.T) {
+	rnd := rand.New(rand.NewSource(33))
+	data := make([]byte, 200_000)
+	rnd.Read(data)
+
+	for _, n := range []int{0, 1, 8, 9, 32, 33, 63, 64, 65, 127, 128, 129, 1_000, 32_768, 32_833, 200_000} {
+		got, err := hash.ReaderAt(bytes.NewReader(data), int64(n))
+		if err != nil {
+			t.Fatalf("n=%d: %v", n, err)
+		}
+		if exp := hash.Bytes(data[:n]); got != exp {
+			t.Errorf("n=%d: got %#x, want %#x", n, got, exp)
+		}
+	}
+
+	// size exceeds input
+	if _, err := hash.ReaderAt(bytes.NewReader(data[:100]), 101); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+}
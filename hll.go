@@ -2,7 +2,9 @@ package zetasketch
 
 import (
 	"fmt"
+	"math/bits"
 
+	"github.com/bsm/zetasketch/hash"
 	"github.com/bsm/zetasketch/hllplus"
 	pb "github.com/bsm/zetasketch/internal/zetasketch"
 	"google.golang.org/protobuf/proto"
@@ -16,10 +18,13 @@ import (
 // A typical value used at Google is 15, which gives an error of about 0.6% while requiring an upper
 // bound of 32KiB of memory.
 //
-// Note that this aggregator is not designed to be thread safe.
+// Note that this aggregator is not designed to be thread safe, see
+// ConcurrentHLL for a thread-safe alternative.
 type HLL struct {
 	h *hllplus.HLL
 	n int64
+	v int32
+	t ValueType
 }
 
 // NewHLL inits a new HLL++ aggregator.
@@ -28,13 +33,79 @@ func NewHLL(cfg *HLLConfig) *HLL {
 	if err != nil {
 		panic(err)
 	}
-	return &HLL{h: h}
+	h.SetEstimator(cfg.estimator())
+	return &HLL{h: h, v: cfg.encodingVersion()}
 }
 
 // Add adds value v to the aggregator.
+// If v is a TypedValue, its type is tracked by the aggregator.
+//
+// Passing v as an interface allocates. Use the typed adders, such as AddInt64
+// or AddString, AddHash or HLLOf to add values without allocations.
 func (h *HLL) Add(v Value) {
+	h.add(v.Sum64(), typeOf(v))
+}
+
+// AddHash adds a hash, pre-computed by one of the functions of the hash
+// package, to the aggregator. The hash is untyped, like a Value returned by
+// HashValue with ValueTypeUnknown. Unlike Add, it never boxes the hash in an
+// interface and so does not allocate.
+func (h *HLL) AddHash(sum uint64) {
+	h.add(sum, ValueTypeUnknown)
+}
+
+// AddString adds a string, like Add with StringValue, without allocating.
+func (h *HLL) AddString(s string) { h.add(hash.String(s), ValueTypeBytes) }
+
+// AddBytes adds a byte slice, like Add with BinaryValue, without allocating.
+func (h *HLL) AddBytes(p []byte) { h.add(hash.Bytes(p), ValueTypeBytes) }
+
+// AddInt32 adds a number, like Add with Int32Value, without allocating.
+func (h *HLL) AddInt32(v int32) { h.add(hash.Int32(v), ValueTypeInt32) }
+
+// AddInt64 adds a number, like Add with Int64Value, without allocating.
+func (h *HLL) AddInt64(v int64) { h.add(hash.Int64(v), ValueTypeInt64) }
+
+// AddUint32 adds a number, like Add with Uint32Value, without allocating.
+func (h *HLL) AddUint32(v uint32) { h.add(hash.Uint32(v), ValueTypeUint32) }
+
+// AddUint64 adds a number, like Add with Uint64Value, without allocating.
+func (h *HLL) AddUint64(v uint64) { h.add(hash.Uint64(v), ValueTypeUint64) }
+
+// AddFloat32 adds a number, like Add with Float32Value, without allocating.
+func (h *HLL) AddFloat32(v float32) { h.add(hash.Float32(v), ValueTypeFloat) }
+
+// AddFloat64 adds a number, like Add with Float64Value, without allocating.
+func (h *HLL) AddFloat64(v float64) { h.add(hash.Float64(v), ValueTypeDouble) }
+
+func (h *HLL) add(sum uint64, t ValueType) {
+	h.track(t)
 	h.n++
-	h.h.Add(v.Sum64())
+	h.h.Add(sum)
+}
+
+// track updates the value type of the aggregator with the type t of an added
+// value. Once values of incompatible types were added, the aggregator is marked
+// as mixed.
+func (h *HLL) track(t ValueType) {
+	if t != h.t && h.t != valueTypeMixed {
+		if t2, err := mergeValueTypes(h.t, t); err == nil {
+			h.t = t2
+		} else {
+			h.t = valueTypeMixed
+		}
+	}
+}
+
+// ValueType returns the type of the values seen. It returns ValueTypeUnknown
+// if no typed values or values of different types were added. Aggregators
+// which have seen values of different types cannot be merged, they are
+// serialized with an unknown value type.
+func (h *HLL) ValueType() ValueType {
+	if h.t == valueTypeMixed {
+		return ValueTypeUnknown
+	}
+	return h.t
 }
 
 // NumValues returns the number of values seen.
@@ -43,23 +114,80 @@ func (h *HLL) NumValues() int64 {
 }
 
 // Merge merges aggregator other into h.
+// Aggregators with incompatible value types cannot be merged.
 func (h *HLL) Merge(other Aggregator) error {
-	h2, ok := other.(*HLL)
-	if !ok {
+	var h2 *HLL
+	switch o := other.(type) {
+	case *HLL:
+		h2 = o
+	case *ConcurrentHLL:
+		h2 = o.Snapshot()
+	default:
 		return fmt.Errorf("cannot merge %T into %T", other, h)
 	}
 
+	t, err := mergeValueTypes(h.t, h2.t)
+	if err != nil {
+		return fmt.Errorf("cannot merge %T: %w", other, err)
+	}
+
 	h.h.Merge(h2.h)
 	h.n += h2.n
+	h.t = t
 	return nil
 }
 
+// Clone creates a copy of the aggregator.
+func (h *HLL) Clone() *HLL {
+	return &HLL{h: h.h.Clone(), n: h.n, v: h.v, t: h.t}
+}
+
+// MergeAll merges multiple aggregators into a new aggregator in a single pass,
+// which is significantly faster than merging them one by one. The aggregators
+// are not modified. Aggregators with incompatible value types cannot be merged.
+func MergeAll(hlls []*HLL) (*HLL, error) {
+	if len(hlls) == 0 {
+		return nil, fmt.Errorf("cannot merge: no aggregators")
+	}
+
+	sketches := make([]*hllplus.HLL, 0, len(hlls))
+	merged := &HLL{v: hlls[0].v}
+	for _, h := range hlls {
+		t, err := mergeValueTypes(merged.t, h.t)
+		if err != nil {
+			return nil, fmt.Errorf("cannot merge %T: %w", h, err)
+		}
+
+		sketches = append(sketches, h.h)
+		merged.n += h.n
+		merged.t = t
+	}
+
+	sketch, err := hllplus.MergeAll(sketches)
+	if err != nil {
+		return nil, err
+	}
+	merged.h = sketch
+	return merged, nil
+}
+
 // Result returns an estimate of the unique of values.
 func (h *HLL) Result() int64 {
 	return h.h.Estimate()
 }
 
-// MarshalBinary serializes aggregator to bytes.
+// Bounds is a cardinality estimate with its error bounds.
+type Bounds = hllplus.Bounds
+
+// ResultBounds returns an estimate of the unique of values together with its
+// standard error and the bounds of the confidence interval at the given
+// confidence level, which must be between 0 and 1 (exclusive), e.g. 0.95.
+func (h *HLL) ResultBounds(confidence float64) (Bounds, error) {
+	return h.h.EstimateBounds(confidence)
+}
+
+// MarshalBinary serializes aggregator to bytes. Aggregators which have seen
+// values of different types are serialized with an unknown value type.
 func (h *HLL) MarshalBinary() ([]byte, error) {
 	return proto.Marshal(h.proto())
 }
@@ -75,16 +203,30 @@ func (h *HLL) UnmarshalBinary(data []byte) error {
 
 func (h *HLL) proto() *pb.AggregatorStateProto {
 	var (
-		encodingVersion int32 = 2
-		aggType               = pb.AggregatorType_HYPERLOGLOG_PLUS_UNIQUE
-		numValues             = int64(h.n)
+		encodingVersion = h.v
+		aggType         = pb.AggregatorType_HYPERLOGLOG_PLUS_UNIQUE
+		numValues       = int64(h.n)
 	)
+	if encodingVersion == 0 {
+		encodingVersion = 2
+	}
 	msg := &pb.AggregatorStateProto{
 		Type:            &aggType,
 		EncodingVersion: &encodingVersion,
 		NumValues:       &numValues,
 	}
-	proto.SetExtension(msg, pb.E_HyperloglogplusUniqueState, h.h.Proto())
+	if t := h.ValueType(); t != ValueTypeUnknown {
+		msg.ValueType = proto.Int32(int32(t))
+	}
+
+	state := h.h.Proto()
+	if encodingVersion == 1 {
+		// precisions are encoded as number of buckets
+		state.PrecisionOrNumBuckets = proto.Int32(1 << state.GetPrecisionOrNumBuckets())
+		state.SparsePrecisionOrNumBuckets = proto.Int32(1 << state.GetSparsePrecisionOrNumBuckets())
+	}
+
+	proto.SetExtension(msg, pb.E_HyperloglogplusUniqueState, state)
 	return msg
 }
 
@@ -92,12 +234,17 @@ func (h *HLL) fromProto(msg *pb.AggregatorStateProto) error {
 	if msg.GetType() != pb.AggregatorType_HYPERLOGLOG_PLUS_UNIQUE {
 		return fmt.Errorf("incompatible binary message: unexpected type %s", msg.GetType().String())
 	}
-	if msg.GetEncodingVersion() != 2 {
-		return fmt.Errorf("incompatible binary message: unsupported encoding version %#v", msg.GetEncodingVersion())
+	encodingVersion := msg.GetEncodingVersion()
+	if encodingVersion != 1 && encodingVersion != 2 {
+		return fmt.Errorf("incompatible binary message: unsupported encoding version %#v", encodingVersion)
 	}
 	if msg.NumValues == nil {
 		return fmt.Errorf("incompatible binary message: no num values")
 	}
+	valueType := ValueType(msg.GetValueType())
+	if !valueType.isValid() {
+		return fmt.Errorf("incompatible binary message: unsupported value type %d", valueType)
+	}
 
 	ext := proto.GetExtension(msg, pb.E_HyperloglogplusUniqueState)
 	hState, ok := ext.(*pb.HyperLogLogPlusUniqueStateProto)
@@ -105,18 +252,70 @@ func (h *HLL) fromProto(msg *pb.AggregatorStateProto) error {
 		return fmt.Errorf("incompatible binary message: invalid HyperLogLog++ state")
 	}
 
+	if encodingVersion == 1 {
+		if err := decodeNumBuckets(hState); err != nil {
+			return err
+		}
+	}
+
 	hll, err := hllplus.NewFromProto(hState)
 	if err != nil {
 		return err
 	}
+	if h.h != nil {
+		hll.SetEstimator(h.h.Estimator())
+	}
 
 	h.h = hll
 	h.n = msg.GetNumValues()
+	h.v = encodingVersion
+	h.t = valueType
 	return nil
 }
 
+// decodeNumBuckets translates the number of buckets stored by encoding version 1
+// into precisions, as expected by encoding version 2.
+func decodeNumBuckets(msg *pb.HyperLogLogPlusUniqueStateProto) error {
+	precision, err := precisionOfNumBuckets(msg.GetPrecisionOrNumBuckets(), hllplus.MinPrecision, hllplus.MaxPrecision)
+	if err != nil {
+		return fmt.Errorf("incompatible binary message: invalid normal number of buckets: %w", err)
+	}
+	sparsePrecision, err := precisionOfNumBuckets(msg.GetSparsePrecisionOrNumBuckets(), 0, hllplus.MaxSparsePrecision)
+	if err != nil {
+		return fmt.Errorf("incompatible binary message: invalid sparse number of buckets: %w", err)
+	}
+
+	msg.PrecisionOrNumBuckets = proto.Int32(int32(precision))
+	msg.SparsePrecisionOrNumBuckets = proto.Int32(int32(sparsePrecision))
+	return nil
+}
+
+func precisionOfNumBuckets(n int32, min, max uint8) (uint8, error) {
+	if n <= 0 || n&(n-1) != 0 {
+		return 0, fmt.Errorf("%d is not a power of two", n)
+	}
+
+	precision := uint8(bits.TrailingZeros32(uint32(n)))
+	if precision < min || precision > max {
+		return 0, fmt.Errorf("%d is not between 2^%d and 2^%d", n, min, max)
+	}
+	return precision, nil
+}
+
 // -----------------------------------------------------------------------
 
+// Estimator selects the algorithm which computes the cardinality estimate of
+// the HLL++ aggregator, see the hllplus package for details.
+type Estimator = hllplus.Estimator
+
+// Supported estimators.
+const (
+	EstimatorHLLPlus         = hllplus.EstimatorHLLPlus
+	EstimatorImproved        = hllplus.EstimatorImproved
+	EstimatorMaxLikelihood   = hllplus.EstimatorMaxLikelihood
+	EstimatorHLLPlusExtended = hllplus.EstimatorHLLPlusExtended
+)
+
 // HLLConfig speficies the configuration parameters for the HLL++ aggregator.
 type HLLConfig struct {
 	// Defaults to 15.
@@ -124,6 +323,16 @@ type HLLConfig struct {
 
 	// If no sparse precision is specified, the default is calculated as precision + 5.
 	SparsePrecision uint8
+
+	// EncodingVersion of the serialized aggregator state. Version 1 is a legacy
+	// format which stores precisions as number of buckets, i.e. 2^precision.
+	// Defaults to 2.
+	EncodingVersion int32
+
+	// Estimator used to compute results from normal registers. The estimator
+	// is not serialized and is retained when unmarshaling into an existing
+	// aggregator. Defaults to EstimatorHLLPlus.
+	Estimator Estimator
 }
 
 func (c *HLLConfig) precision() uint8 {
@@ -143,3 +352,17 @@ func (c *HLLConfig) sparsePrecision() uint8 {
 	}
 	return hllplus.MaxSparsePrecision
 }
+
+func (c *HLLConfig) estimator() Estimator {
+	if c != nil && c.Estimator <= EstimatorHLLPlusExtended {
+		return c.Estimator
+	}
+	return EstimatorHLLPlus
+}
+
+func (c *HLLConfig) encodingVersion() int32 {
+	if c != nil && c.EncodingVersion == 1 {
+		return 1
+	}
+	return 2
+}
@@ -0,0 +1,217 @@
+package zetasketch
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/bsm/zetasketch/hash"
+)
+
+// ConcurrentHLL is a thread-safe HLL++ aggregator.
+//
+// Values are distributed across multiple shards by their hash, each shard is
+// an independent HLL guarded by its own lock. Concurrent calls to Add therefore
+// rarely contend. Shards are merged on read, which yields exactly the same
+// registers as adding all values to a single HLL. Reads are comparatively
+// expensive and memory usage is up to one HLL per shard.
+//
+// The zero value is an aggregator with the default configuration, its shards
+// are allocated on first use.
+type ConcurrentHLL struct {
+	cfg    HLLConfig
+	shards []hllShard
+	once   sync.Once
+}
+
+type hllShard struct {
+	mu sync.Mutex
+	h  *HLL
+	_  [48]byte // pad to cache line size to avoid false sharing
+}
+
+// NewConcurrentHLL inits a new thread-safe HLL++ aggregator with one shard
+// per available CPU.
+func NewConcurrentHLL(cfg *HLLConfig) *ConcurrentHLL {
+	c := new(ConcurrentHLL)
+	if cfg != nil {
+		c.cfg = *cfg
+	}
+	c.init()
+	return c
+}
+
+// init allocates the shards, unless they were allocated already.
+func (c *ConcurrentHLL) init() {
+	c.once.Do(func() {
+		if c.shards != nil {
+			return
+		}
+
+		c.shards = make([]hllShard, runtime.GOMAXPROCS(0))
+		for i := range c.shards {
+			c.shards[i].h = NewHLL(&c.cfg)
+		}
+	})
+}
+
+// Add adds value v to the aggregator.
+// If v is a TypedValue, its type is tracked by the aggregator.
+// Like HLL.Add, it allocates, see the typed adders for alternatives.
+func (c *ConcurrentHLL) Add(v Value) {
+	c.add(v.Sum64(), typeOf(v))
+}
+
+// AddHash adds an untyped, pre-computed hash to the aggregator, see
+// HLL.AddHash.
+func (c *ConcurrentHLL) AddHash(sum uint64) {
+	c.add(sum, ValueTypeUnknown)
+}
+
+// AddString adds a string without allocating, see HLL.AddString.
+func (c *ConcurrentHLL) AddString(s string) { c.add(hash.String(s), ValueTypeBytes) }
+
+// AddBytes adds a byte slice without allocating, see HLL.AddBytes.
+func (c *ConcurrentHLL) AddBytes(p []byte) { c.add(hash.Bytes(p), ValueTypeBytes) }
+
+// AddInt32 adds a number without allocating, see HLL.AddInt32.
+func (c *ConcurrentHLL) AddInt32(v int32) { c.add(hash.Int32(v), ValueTypeInt32) }
+
+// AddInt64 adds a number without allocating, see HLL.AddInt64.
+func (c *ConcurrentHLL) AddInt64(v int64) { c.add(hash.Int64(v), ValueTypeInt64) }
+
+// AddUint32 adds a number without allocating, see HLL.AddUint32.
+func (c *ConcurrentHLL) AddUint32(v uint32) { c.add(hash.Uint32(v), ValueTypeUint32) }
+
+// AddUint64 adds a number without allocating, see HLL.AddUint64.
+func (c *ConcurrentHLL) AddUint64(v uint64) { c.add(hash.Uint64(v), ValueTypeUint64) }
+
+// AddFloat32 adds a number without allocating, see HLL.AddFloat32.
+func (c *ConcurrentHLL) AddFloat32(v float32) { c.add(hash.Float32(v), ValueTypeFloat) }
+
+// AddFloat64 adds a number without allocating, see HLL.AddFloat64.
+func (c *ConcurrentHLL) AddFloat64(v float64) { c.add(hash.Float64(v), ValueTypeDouble) }
+
+func (c *ConcurrentHLL) add(sum uint64, t ValueType) {
+	c.init()
+	s := &c.shards[sum%uint64(len(c.shards))]
+	s.mu.Lock()
+	s.h.add(sum, t)
+	s.mu.Unlock()
+}
+
+// NumValues returns the number of values seen.
+func (c *ConcurrentHLL) NumValues() int64 {
+	c.init()
+	var n int64
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		n += s.h.NumValues()
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// Merge merges aggregator other into c.
+// Aggregators with incompatible value types cannot be merged.
+func (c *ConcurrentHLL) Merge(other Aggregator) error {
+	var h2 *HLL
+	switch o := other.(type) {
+	case *HLL:
+		h2 = o
+	case *ConcurrentHLL:
+		h2 = o.Snapshot()
+	default:
+		return fmt.Errorf("cannot merge %T into %T", other, c)
+	}
+
+	// check compatibility across all shards first
+	c.init()
+	c.lockAll()
+	defer c.unlockAll()
+
+	for i := range c.shards {
+		if _, err := mergeValueTypes(c.shards[i].h.t, h2.t); err != nil {
+			return fmt.Errorf("cannot merge %T: %w", other, err)
+		}
+	}
+	return c.shards[0].h.Merge(h2)
+}
+
+// Snapshot returns the merged state of all shards as a HLL.
+func (c *ConcurrentHLL) Snapshot() *HLL {
+	c.init()
+	hlls := make([]*HLL, len(c.shards))
+	c.lockAll()
+	defer c.unlockAll()
+
+	// Shards may have seen values of different types, which are tracked like
+	// Add does instead of failing the merge.
+	for i := range c.shards {
+		s := c.shards[i].h
+		hlls[i] = &HLL{h: s.h, n: s.n, v: s.v}
+	}
+	h, _ := MergeAll(hlls) // cannot fail, there is at least one shard
+	for i := range c.shards {
+		h.track(c.shards[i].h.t)
+	}
+	return h
+}
+
+// ValueType returns the type of the values seen, see HLL.ValueType.
+func (c *ConcurrentHLL) ValueType() ValueType {
+	return c.Snapshot().ValueType()
+}
+
+// Result returns an estimate of the unique of values.
+func (c *ConcurrentHLL) Result() int64 {
+	return c.Snapshot().Result()
+}
+
+// ResultBounds returns an estimate of the unique of values together with its
+// error bounds, see HLL.ResultBounds.
+func (c *ConcurrentHLL) ResultBounds(confidence float64) (Bounds, error) {
+	return c.Snapshot().ResultBounds(confidence)
+}
+
+// MarshalBinary serializes aggregator to bytes. The result is the same as
+// for a HLL and can be deserialized by HLL.UnmarshalBinary.
+func (c *ConcurrentHLL) MarshalBinary() ([]byte, error) {
+	return c.Snapshot().MarshalBinary()
+}
+
+// UnmarshalBinary deserializes aggregator from bytes. It adopts the
+// configuration of the serialized state and must not be called concurrently
+// with other methods.
+func (c *ConcurrentHLL) UnmarshalBinary(data []byte) error {
+	h := new(HLL)
+	if err := h.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	h.h.SetEstimator(c.cfg.estimator())
+
+	c2 := NewConcurrentHLL(&HLLConfig{
+		Precision:       h.h.Precision(),
+		SparsePrecision: h.h.SparsePrecision(),
+		EncodingVersion: h.v,
+		Estimator:       c.cfg.Estimator,
+	})
+	c2.shards[0].h = h
+
+	c.cfg, c.shards = c2.cfg, c2.shards
+	c.once.Do(func() {}) // shards are allocated
+	return nil
+}
+
+func (c *ConcurrentHLL) lockAll() {
+	for i := range c.shards {
+		c.shards[i].mu.Lock()
+	}
+}
+
+func (c *ConcurrentHLL) unlockAll() {
+	for i := range c.shards {
+		c.shards[i].mu.Unlock()
+	}
+}
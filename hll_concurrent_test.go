@@ -0,0 +1,193 @@
+package zetasketch_test
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/bsm/zetasketch"
+)
+
+var _ zetasketch.Aggregator = (*zetasketch.ConcurrentHLL)(nil)
+
+func TestConcurrentHLL(t *testing.T) {
+	for _, n := range []int{1_000, 100_000} {
+		subject := zetasketch.NewConcurrentHLL(nil)
+		expect := zetasketch.NewHLL(nil)
+		for i := range n {
+			expect.Add(zetasketch.Uint64Value(uint64(i)))
+		}
+
+		var wg sync.WaitGroup
+		for w := range 8 {
+			wg.Go(func() {
+				for i := w; i < n; i += 8 {
+					subject.Add(zetasketch.Uint64Value(uint64(i)))
+				}
+			})
+		}
+		wg.Go(func() {
+			for range 10 {
+				_ = subject.Result()
+				if _, err := subject.MarshalBinary(); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+		wg.Wait()
+
+		if got, exp := subject.NumValues(), int64(n); got != exp {
+			t.Errorf("n=%d NumValues: got %d, want %d", n, got, exp)
+		}
+		if got, exp := subject.Result(), expect.Result(); got != exp {
+			t.Errorf("n=%d Result: got %d, want %d", n, got, exp)
+		}
+		if got, exp := subject.ValueType(), zetasketch.ValueTypeUint64; got != exp {
+			t.Errorf("n=%d ValueType: got %s, want %s", n, got, exp)
+		}
+
+		got, err := subject.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp, err := expect.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, exp) {
+			t.Errorf("n=%d MarshalBinary: got %d bytes, want %d bytes", n, len(got), len(exp))
+		}
+	}
+}
+
+func TestConcurrentHLL_Merge(t *testing.T) {
+	subject := zetasketch.NewConcurrentHLL(nil)
+	for i := range 1_000 {
+		subject.Add(zetasketch.Uint64Value(uint64(i)))
+	}
+
+	other := zetasketch.NewHLL(nil)
+	for i := 800; i < 1_200; i++ {
+		other.Add(zetasketch.Uint64Value(uint64(i)))
+	}
+	if err := subject.Merge(other); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := subject.NumValues(), int64(1_400); got != exp {
+		t.Errorf("NumValues: got %d, want %d", got, exp)
+	}
+	if got, exp := subject.Result(), int64(1_201); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+
+	// merge into HLL
+	if err := other.Merge(subject); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := other.Result(), int64(1_201); got != exp {
+		t.Errorf("HLL.Merge: got %d, want %d", got, exp)
+	}
+
+	strs := zetasketch.NewHLL(nil)
+	strs.Add(zetasketch.StringValue("x"))
+	if err := subject.Merge(strs); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestConcurrentHLL_mixedValueTypes(t *testing.T) {
+	subject := zetasketch.NewConcurrentHLL(nil)
+	for i := range 100 {
+		subject.Add(zetasketch.Uint64Value(uint64(i)))
+		subject.Add(zetasketch.StringValue(strconv.Itoa(i)))
+	}
+	if got, exp := subject.ValueType(), zetasketch.ValueTypeUnknown; got != exp {
+		t.Errorf("ValueType: got %s, want %s", got, exp)
+	}
+	if got, exp := subject.Result(), int64(200); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+}
+
+func TestConcurrentHLL_zeroValue(t *testing.T) {
+	var subject zetasketch.ConcurrentHLL
+	if got := subject.Result(); got != 0 {
+		t.Errorf("Result: got %d, want 0", got)
+	}
+
+	expect := zetasketch.NewHLL(nil)
+	for i := range 1_000 {
+		expect.Add(zetasketch.Uint64Value(uint64(i)))
+	}
+
+	// shards are allocated on first use, also by concurrent calls
+	var subject2 zetasketch.ConcurrentHLL
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Go(func() {
+			for i := w; i < 1_000; i += 8 {
+				subject2.Add(zetasketch.Uint64Value(uint64(i)))
+			}
+		})
+	}
+	wg.Wait()
+
+	if got, exp := subject2.NumValues(), int64(1_000); got != exp {
+		t.Errorf("NumValues: got %d, want %d", got, exp)
+	}
+	got, err := subject2.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp, err := expect.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, exp) {
+		t.Errorf("MarshalBinary: got %d bytes, want %d bytes", len(got), len(exp))
+	}
+
+	var subject3 zetasketch.ConcurrentHLL
+	if err := subject3.Merge(expect); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := subject3.Result(), expect.Result(); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+}
+
+func TestConcurrentHLL_UnmarshalBinary(t *testing.T) {
+	source := newTestHLL()
+	data, err := source.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject := new(zetasketch.ConcurrentHLL)
+	if err := subject.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := subject.NumValues(), int64(1_500); got != exp {
+		t.Errorf("NumValues: got %d, want %d", got, exp)
+	}
+	if got, exp := subject.Result(), int64(1_000); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+
+	subject.Add(zetasketch.Uint64Value(5_000))
+	if got, exp := subject.Result(), int64(1_001); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+}
+
+func BenchmarkConcurrentHLL_Add(b *testing.B) {
+	subject := zetasketch.NewConcurrentHLL(nil)
+	b.RunParallel(func(pb *testing.PB) {
+		var i uint64
+		for pb.Next() {
+			i++
+			subject.Add(zetasketch.Uint64Value(i))
+		}
+	})
+}
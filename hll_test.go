@@ -4,6 +4,9 @@ import (
 	"testing"
 
 	"github.com/bsm/zetasketch"
+	"github.com/bsm/zetasketch/hash"
+	pb "github.com/bsm/zetasketch/internal/zetasketch"
+	"google.golang.org/protobuf/proto"
 )
 
 var _ zetasketch.Aggregator = (*zetasketch.HLL)(nil)
@@ -33,6 +36,27 @@ func TestHLL_Result(t *testing.T) {
 	}
 }
 
+func TestHLL_ResultBounds(t *testing.T) {
+	subject := newTestHLL()
+	b, err := subject.ResultBounds(0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := b.Estimate, int64(1_000); got != exp {
+		t.Errorf("Estimate: got %d, want %d", got, exp)
+	}
+	if got, exp := b.Lower, int64(998); got != exp {
+		t.Errorf("Lower: got %d, want %d", got, exp)
+	}
+	if got, exp := b.Upper, int64(1_002); got != exp {
+		t.Errorf("Upper: got %d, want %d", got, exp)
+	}
+
+	if _, err := subject.ResultBounds(1.5); err == nil {
+		t.Error("expected error")
+	}
+}
+
 func TestHLL_Merge(t *testing.T) {
 	subject := newTestHLL()
 
@@ -47,7 +71,7 @@ func TestHLL_Merge(t *testing.T) {
 	if got, exp := subject.NumValues(), int64(1_900); got != exp {
 		t.Errorf("NumValues: got %d, want %d", got, exp)
 	}
-	if got, exp := subject.Result(), int64(1_207); got != exp {
+	if got, exp := subject.Result(), int64(1_201); got != exp {
 		t.Errorf("Result: got %d, want %d", got, exp)
 	}
 
@@ -60,6 +84,237 @@ func TestHLL_Merge(t *testing.T) {
 	}
 }
 
+func TestMergeAll(t *testing.T) {
+	hlls := []*zetasketch.HLL{newTestHLL(), zetasketch.NewHLL(nil), zetasketch.NewHLL(nil)}
+	for i := 800; i < 1_200; i++ {
+		hlls[1].Add(zetasketch.Uint64Value(uint64(i)))
+	}
+
+	subject, err := zetasketch.MergeAll(hlls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := subject.NumValues(), int64(1_900); got != exp {
+		t.Errorf("NumValues: got %d, want %d", got, exp)
+	}
+	if got, exp := subject.Result(), int64(1_201); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+	if got, exp := subject.ValueType(), zetasketch.ValueTypeUint64; got != exp {
+		t.Errorf("ValueType: got %s, want %s", got, exp)
+	}
+
+	// inputs are not modified:
+	if got, exp := hlls[0].NumValues(), int64(1_500); got != exp {
+		t.Errorf("NumValues: got %d, want %d", got, exp)
+	}
+
+	strs := zetasketch.NewHLL(nil)
+	strs.Add(zetasketch.StringValue("foo"))
+	if _, err := zetasketch.MergeAll(append(hlls, strs)); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := zetasketch.MergeAll(nil); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestHLL_ValueType(t *testing.T) {
+	subject := zetasketch.NewHLL(nil)
+	if got, exp := subject.ValueType(), zetasketch.ValueTypeUnknown; got != exp {
+		t.Errorf("got %s, want %s", got, exp)
+	}
+
+	subject.Add(zetasketch.StringValue("foo"))
+	subject.Add(zetasketch.BinaryValue([]byte("bar")))
+	if got, exp := subject.ValueType(), zetasketch.ValueTypeBytes; got != exp {
+		t.Errorf("got %s, want %s", got, exp)
+	}
+
+	// values of different types:
+	subject.Add(zetasketch.Uint64Value(1))
+	if got, exp := subject.ValueType(), zetasketch.ValueTypeUnknown; got != exp {
+		t.Errorf("got %s, want %s", got, exp)
+	}
+}
+
+func TestHLL_Add_signed(t *testing.T) {
+	subject := zetasketch.NewHLL(nil)
+	for i := range 1_000 {
+		subject.Add(zetasketch.Int64Value(int64(i) - 500))
+	}
+	if got, exp := subject.Result(), int64(1_000); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+	if got, exp := subject.ValueType(), zetasketch.ValueTypeInt64; got != exp {
+		t.Errorf("ValueType: got %s, want %s", got, exp)
+	}
+
+	// Int32Value hashes the 4 2s-complement bytes, like Hash.of(int) in Java:
+	if got, exp := zetasketch.Int32Value(-7).Sum64(), zetasketch.BinaryValue([]byte{0xf9, 0xff, 0xff, 0xff}).Sum64(); got != exp {
+		t.Errorf("Int32Value: got %#x, want %#x", got, exp)
+	}
+}
+
+func TestHLL_Add_hashed(t *testing.T) {
+	subject := zetasketch.NewHLL(nil)
+	subject.Add(zetasketch.HashValue(hash.String("foo"), zetasketch.ValueTypeBytes))
+	subject.Add(zetasketch.HashValue(hash.String("bar"), zetasketch.ValueTypeUnknown))
+	subject.Add(zetasketch.StringValue("foo"))
+
+	if got, exp := subject.Result(), int64(2); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+	if got, exp := subject.ValueType(), zetasketch.ValueTypeBytes; got != exp {
+		t.Errorf("ValueType: got %s, want %s", got, exp)
+	}
+}
+
+func TestHLL_Add_tuple(t *testing.T) {
+	subject := zetasketch.NewHLL(nil)
+	for _, pair := range [][2]string{{"ab", "c"}, {"a", "bc"}, {"ab", "c"}, {"c", "ab"}} {
+		subject.Add(zetasketch.TupleValue(zetasketch.StringValue(pair[0]), zetasketch.StringValue(pair[1])))
+	}
+	if got, exp := subject.Result(), int64(3); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+
+	// same scheme as hash.Tuple:
+	v := zetasketch.TupleValue(zetasketch.Int64Value(1), zetasketch.StringValue("x"))
+	if got, exp := v.Sum64(), hash.Tuple(hash.Int64(1), hash.String("x")); got != exp {
+		t.Errorf("Sum64: got %#x, want %#x", got, exp)
+	}
+}
+
+func TestHLL_Merge_valueType(t *testing.T) {
+	uints := newTestHLL()
+	strs := zetasketch.NewHLL(nil)
+	strs.Add(zetasketch.StringValue("foo"))
+
+	if err := uints.Merge(strs); err == nil {
+		t.Error("expected error")
+	}
+	if got, exp := uints.NumValues(), int64(1_500); got != exp {
+		t.Errorf("NumValues: got %d, want %d", got, exp)
+	}
+
+	// unknown value types are compatible:
+	empty := zetasketch.NewHLL(nil)
+	if err := empty.Merge(uints); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := empty.ValueType(), zetasketch.ValueTypeUint64; got != exp {
+		t.Errorf("ValueType: got %s, want %s", got, exp)
+	}
+	if err := uints.Merge(zetasketch.NewHLL(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	// values of different types are incompatible with any type:
+	mixed := zetasketch.NewHLL(nil)
+	mixed.Add(zetasketch.StringValue("foo"))
+	mixed.Add(zetasketch.Uint64Value(1))
+	for _, other := range []*zetasketch.HLL{uints, strs, zetasketch.NewHLL(nil)} {
+		if err := mixed.Merge(other); err == nil {
+			t.Errorf("expected error merging %s into mixed", other.ValueType())
+		}
+		if err := other.Merge(mixed); err == nil {
+			t.Errorf("expected error merging mixed into %s", other.ValueType())
+		}
+	}
+	if _, err := zetasketch.MergeAll([]*zetasketch.HLL{zetasketch.NewHLL(nil), mixed}); err == nil {
+		t.Error("MergeAll: expected error")
+	}
+
+	// but can be marshaled, with an unknown value type:
+	data, err := mixed.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := new(zetasketch.HLL)
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := restored.ValueType(), zetasketch.ValueTypeUnknown; got != exp {
+		t.Errorf("ValueType: got %s, want %s", got, exp)
+	}
+	if got, exp := restored.Result(), int64(2); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+}
+
+func TestHLL_Merge_signedUnsigned(t *testing.T) {
+	// INT64 and UINT64 values are hashed identically
+	ints := zetasketch.NewHLL(nil)
+	uints := zetasketch.NewHLL(nil)
+	for i := range 1_000 {
+		ints.Add(zetasketch.Int64Value(int64(i)))
+		uints.Add(zetasketch.Uint64Value(uint64(i + 500)))
+	}
+
+	if err := uints.Merge(ints); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := uints.ValueType(), zetasketch.ValueTypeInt64; got != exp {
+		t.Errorf("ValueType: got %s, want %s", got, exp)
+	}
+	expect := zetasketch.NewHLL(nil)
+	for i := range 1_500 {
+		expect.Add(zetasketch.Int64Value(int64(i)))
+	}
+	if got, exp := uints.Result(), expect.Result(); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+
+	subject := zetasketch.NewHLL(nil)
+	subject.Add(zetasketch.Uint64Value(1))
+	subject.Add(zetasketch.Int64Value(1))
+	if got, exp := subject.ValueType(), zetasketch.ValueTypeInt64; got != exp {
+		t.Errorf("ValueType: got %s, want %s", got, exp)
+	}
+	if got, exp := subject.Result(), int64(1); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+}
+
+func TestHLL_UnmarshalBinary_customValueType(t *testing.T) {
+	msg := &pb.AggregatorStateProto{
+		Type:            pb.AggregatorType_HYPERLOGLOG_PLUS_UNIQUE.Enum(),
+		NumValues:       proto.Int64(0),
+		EncodingVersion: proto.Int32(2),
+		ValueType:       proto.Int32(1234),
+	}
+	proto.SetExtension(msg, pb.E_HyperloglogplusUniqueState, &pb.HyperLogLogPlusUniqueStateProto{
+		PrecisionOrNumBuckets:       proto.Int32(15),
+		SparsePrecisionOrNumBuckets: proto.Int32(20),
+	})
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject := new(zetasketch.HLL)
+	if err := subject.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := subject.ValueType(), zetasketch.ValueType(1234); got != exp {
+		t.Errorf("ValueType: got %s, want %s", got, exp)
+	}
+	if _, err := zetasketch.EstimateBytes(data); err != nil {
+		t.Fatal(err)
+	}
+
+	// custom types are only compatible with themselves
+	other := zetasketch.NewHLL(nil)
+	other.Add(zetasketch.HashValue(1, 1234))
+	if err := subject.Merge(other); err != nil {
+		t.Error(err)
+	}
+	if err := subject.Merge(newTestHLL()); err == nil {
+		t.Error("expected error")
+	}
+}
+
 func TestHLL_MarshalBinary(t *testing.T) {
 	subject := newTestHLL()
 
@@ -78,4 +333,166 @@ func TestHLL_MarshalBinary(t *testing.T) {
 	if got, exp := subject.Result(), int64(1_000); got != exp {
 		t.Errorf("Result: got %d, want %d", got, exp)
 	}
+	if got, exp := subject.ValueType(), zetasketch.ValueTypeUint64; got != exp {
+		t.Errorf("ValueType: got %s, want %s", got, exp)
+	}
+
+	msg := new(pb.AggregatorStateProto)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := msg.GetValueType(), int32(pb.DefaultOpsType_UINT64); got != exp {
+		t.Errorf("value_type: got %d, want %d", got, exp)
+	}
+}
+
+func TestHLL_MarshalBinary_encodingVersion1(t *testing.T) {
+	subject := zetasketch.NewHLL(&zetasketch.HLLConfig{Precision: 12, EncodingVersion: 1})
+	for i := range 1_000 {
+		subject.Add(zetasketch.Uint64Value(uint64(i)))
+	}
+
+	data, err := subject.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := new(pb.AggregatorStateProto)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := msg.GetEncodingVersion(), int32(1); got != exp {
+		t.Errorf("EncodingVersion: got %d, want %d", got, exp)
+	}
+	state := proto.GetExtension(msg, pb.E_HyperloglogplusUniqueState).(*pb.HyperLogLogPlusUniqueStateProto)
+	if got, exp := state.GetPrecisionOrNumBuckets(), int32(1<<12); got != exp {
+		t.Errorf("PrecisionOrNumBuckets: got %d, want %d", got, exp)
+	}
+	if got, exp := state.GetSparsePrecisionOrNumBuckets(), int32(1<<17); got != exp {
+		t.Errorf("SparsePrecisionOrNumBuckets: got %d, want %d", got, exp)
+	}
+
+	restored := new(zetasketch.HLL)
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := restored.Result(), subject.Result(); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+
+	// merge legacy state with current state:
+	other := newTestHLL()
+	if err := other.Merge(restored); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := other.Result(), int64(1_004); got != exp {
+		t.Errorf("Merge: got %d, want %d", got, exp)
+	}
+}
+
+func TestHLL_UnmarshalBinary_encodingVersion1(t *testing.T) {
+	cases := []struct {
+		precision, sparsePrecision int32
+		ok                         bool
+	}{
+		{1 << 15, 1 << 20, true},
+		{1 << 10, 1 << 25, true},
+		{1 << 15, 1 << 15, true},
+		{15, 20, false},           // precisions, not number of buckets
+		{3 << 14, 1 << 20, false}, // not a power of two
+		{1 << 9, 1 << 20, false},  // precision too small
+		{1 << 25, 1 << 25, false}, // precision too large
+		{1 << 15, 1 << 26, false}, // sparse precision too large
+		{1 << 15, 1 << 14, false}, // sparse precision < precision
+	}
+	for _, tc := range cases {
+		msg := &pb.AggregatorStateProto{
+			Type:            pb.AggregatorType_HYPERLOGLOG_PLUS_UNIQUE.Enum(),
+			NumValues:       proto.Int64(0),
+			EncodingVersion: proto.Int32(1),
+		}
+		proto.SetExtension(msg, pb.E_HyperloglogplusUniqueState, &pb.HyperLogLogPlusUniqueStateProto{
+			PrecisionOrNumBuckets:       proto.Int32(tc.precision),
+			SparsePrecisionOrNumBuckets: proto.Int32(tc.sparsePrecision),
+		})
+		data, err := proto.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = new(zetasketch.HLL).UnmarshalBinary(data)
+		if tc.ok && err != nil {
+			t.Errorf("(%d, %d): unexpected error: %v", tc.precision, tc.sparsePrecision, err)
+		} else if !tc.ok && err == nil {
+			t.Errorf("(%d, %d): expected error", tc.precision, tc.sparsePrecision)
+		}
+	}
+}
+
+func TestHLL_Clone(t *testing.T) {
+	subject := newTestHLL()
+	clone := subject.Clone()
+	clone.Add(zetasketch.Uint64Value(5_000))
+
+	if got, exp := subject.NumValues(), int64(1_500); got != exp {
+		t.Errorf("NumValues: got %d, want %d", got, exp)
+	}
+	if got, exp := subject.Result(), int64(1_000); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+	if got, exp := clone.Result(), int64(1_001); got != exp {
+		t.Errorf("clone.Result: got %d, want %d", got, exp)
+	}
+	if got, exp := clone.ValueType(), zetasketch.ValueTypeUint64; got != exp {
+		t.Errorf("clone.ValueType: got %s, want %s", got, exp)
+	}
+}
+
+func TestHLL_estimator(t *testing.T) {
+	cfg := &zetasketch.HLLConfig{Precision: 10, Estimator: zetasketch.EstimatorImproved}
+	subject := zetasketch.NewHLL(cfg)
+	for i := range 5_000 {
+		subject.Add(zetasketch.Int64Value(int64(i)))
+	}
+
+	data, err := subject.MarshalBinary()
+	if err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+
+	// the estimator is retained by the receiver
+	restored := zetasketch.NewHLL(cfg)
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if got, exp := restored.Result(), subject.Result(); got != exp {
+		t.Errorf("restored: got %d, want %d", got, exp)
+	}
+
+	fallback := zetasketch.NewHLL(&zetasketch.HLLConfig{Precision: 10})
+	if err := fallback.UnmarshalBinary(data); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if got := fallback.Result(); got == subject.Result() {
+		t.Errorf("default estimator: got %d, want a different estimate", got)
+	}
+
+	// views and EstimateBytes use the default estimator, unless configured
+	if got, err := zetasketch.EstimateBytes(data); err != nil {
+		t.Fatal("expected no error, got", err)
+	} else if exp := fallback.Result(); got != exp {
+		t.Errorf("EstimateBytes: got %d, want %d", got, exp)
+	}
+
+	view, err := zetasketch.NewHLLView(data)
+	if err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if got, exp := view.Result(), fallback.Result(); got != exp {
+		t.Errorf("view: got %d, want %d", got, exp)
+	}
+	view.SetEstimator(zetasketch.EstimatorImproved)
+	if got, exp := view.Result(), subject.Result(); got != exp {
+		t.Errorf("view: got %d, want %d", got, exp)
+	}
 }
@@ -0,0 +1,171 @@
+package zetasketch_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bsm/zetasketch"
+	pb "github.com/bsm/zetasketch/internal/zetasketch"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestHLLOf(t *testing.T) {
+	subject := zetasketch.NewInt64HLL(nil)
+	for i := range 1_000 {
+		subject.Add(int64(i) - 500)
+	}
+	if got, exp := subject.NumValues(), int64(1_000); got != exp {
+		t.Errorf("NumValues: got %d, want %d", got, exp)
+	}
+	if got, exp := subject.Result(), int64(1_000); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+	if got, exp := subject.HLL().ValueType(), zetasketch.ValueTypeInt64; got != exp {
+		t.Errorf("ValueType: got %s, want %s", got, exp)
+	}
+
+	// INT64 values are hashed by their two's complement:
+	untyped := zetasketch.NewHLL(nil)
+	for i := range 1_000 {
+		untyped.Add(zetasketch.Uint64Value(uint64(int64(i) - 500)))
+	}
+	if got, exp := subject.Result(), untyped.Result(); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+}
+
+func TestHLLOf_stringsAndBytes(t *testing.T) {
+	strs := zetasketch.NewStringHLL(nil)
+	bins := zetasketch.NewBytesHLL(nil)
+	for _, s := range []string{"foo", "bar", "baz", "foo"} {
+		strs.Add(s)
+		bins.Add([]byte(s))
+	}
+
+	data1, err := strs.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data2, err := bins.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data1, data2) {
+		t.Errorf("expected STRING and BYTES sketches to be identical")
+	}
+}
+
+func TestHLLOf_MarshalBinary(t *testing.T) {
+	subject := zetasketch.NewUint64HLL(nil)
+
+	// value type is stamped, even if empty:
+	data, err := subject.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := new(pb.AggregatorStateProto)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := msg.GetValueType(), int32(pb.DefaultOpsType_UINT64); got != exp {
+		t.Errorf("value_type: got %d, want %d", got, exp)
+	}
+
+	// restore into compatible types:
+	if err := zetasketch.NewUint64HLL(nil).UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zetasketch.NewStringHLL(nil).UnmarshalBinary(data); err == nil {
+		t.Error("expected error")
+	}
+
+	// untyped, unknown state is accepted and typed on restore:
+	data, _ = zetasketch.NewHLL(nil).MarshalBinary()
+	restored := zetasketch.NewStringHLL(nil)
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := restored.HLL().ValueType(), zetasketch.ValueTypeBytes; got != exp {
+		t.Errorf("ValueType: got %s, want %s", got, exp)
+	}
+}
+
+func TestHLLOf_estimator(t *testing.T) {
+	cfg := &zetasketch.HLLConfig{Precision: 10, Estimator: zetasketch.EstimatorImproved}
+	subject := zetasketch.NewInt64HLL(cfg)
+	for i := range 5_000 {
+		subject.Add(int64(i))
+	}
+
+	data, err := subject.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the estimator is retained by the receiver
+	restored := zetasketch.NewInt64HLL(cfg)
+	underlying := restored.HLL()
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := restored.Result(), subject.Result(); got != exp {
+		t.Errorf("restored: got %d, want %d", got, exp)
+	}
+	if got, exp := underlying.Result(), subject.Result(); got != exp {
+		t.Errorf("underlying: got %d, want %d", got, exp)
+	}
+
+	fallback := zetasketch.NewInt64HLL(&zetasketch.HLLConfig{Precision: 10})
+	if err := fallback.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got := fallback.Result(); got == subject.Result() {
+		t.Errorf("default estimator: got %d, want a different estimate", got)
+	}
+
+	// a failed restore leaves the receiver unchanged
+	if err := zetasketch.NewStringHLL(cfg).UnmarshalBinary(data); err == nil {
+		t.Error("expected error")
+	}
+	if err := restored.UnmarshalBinary([]byte("garbage")); err == nil {
+		t.Error("expected error")
+	}
+	if got, exp := restored.Result(), subject.Result(); got != exp {
+		t.Errorf("restored: got %d, want %d", got, exp)
+	}
+}
+
+func TestHLLOf_Merge(t *testing.T) {
+	subject := zetasketch.NewStringHLL(nil)
+	subject.Add("foo")
+	subject.Add("bar")
+
+	other := zetasketch.NewStringHLL(nil)
+	other.Add("bar")
+	other.Add("baz")
+
+	if err := subject.Merge(other); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := subject.Result(), int64(3); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+}
+
+func BenchmarkHLLOf_Add(b *testing.B) {
+	b.Run("string", func(b *testing.B) {
+		subject := zetasketch.NewStringHLL(nil)
+		str := "user:8d2f9a41-0e97-4e1a"
+		b.ReportAllocs()
+		for b.Loop() {
+			subject.Add(str)
+		}
+	})
+	b.Run("int64", func(b *testing.B) {
+		subject := zetasketch.NewInt64HLL(nil)
+		b.ReportAllocs()
+		for i := 0; b.Loop(); i++ {
+			subject.Add(int64(i & 0xff))
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package zetasketch
+
+import (
+	"fmt"
+
+	"github.com/bsm/zetasketch/hllplus"
+)
+
+// HLLView is a read-only view of a serialized HLL++ aggregator. It references
+// the serialized data directly, without copying or decoding it, which must not
+// be modified while the view is in use.
+type HLLView struct {
+	v *hllplus.View
+	n int64
+	t ValueType
+}
+
+// NewHLLView inits a view of a serialized HLL++ aggregator, as produced by
+// HLL.MarshalBinary.
+func NewHLLView(data []byte) (*HLLView, error) {
+	var w hllWire
+	if err := w.parse(data); err != nil {
+		return nil, err
+	}
+
+	v, err := hllplus.NewView(w.precision, w.sparsePrecision, w.data, w.sparseData)
+	if err != nil {
+		return nil, err
+	}
+	return &HLLView{v: v, n: w.numValues, t: w.valueType}, nil
+}
+
+// NumValues returns the number of values seen.
+func (v *HLLView) NumValues() int64 {
+	return v.n
+}
+
+// ValueType returns the type of the values seen, see HLL.ValueType.
+func (v *HLLView) ValueType() ValueType {
+	return v.t
+}
+
+// SetEstimator sets the estimator used by Result, see HLLConfig.Estimator.
+// Defaults to EstimatorHLLPlus.
+func (v *HLLView) SetEstimator(e Estimator) {
+	v.v.SetEstimator(e)
+}
+
+// Result returns an estimate of the unique of values.
+func (v *HLLView) Result() int64 {
+	return v.v.Estimate()
+}
+
+// Sketch returns the underlying sketch view.
+func (v *HLLView) Sketch() *hllplus.View {
+	return v.v
+}
+
+// MergeView merges the aggregator of view v into h.
+// Aggregators with incompatible value types cannot be merged.
+func (h *HLL) MergeView(v *HLLView) error {
+	t, err := mergeValueTypes(h.t, v.t)
+	if err != nil {
+		return fmt.Errorf("cannot merge %T: %w", v, err)
+	}
+
+	h.h.MergeView(v.v)
+	h.n += v.n
+	h.t = t
+	return nil
+}
@@ -0,0 +1,69 @@
+package zetasketch_test
+
+import (
+	"testing"
+
+	"github.com/bsm/zetasketch"
+)
+
+func TestHLLView(t *testing.T) {
+	for name, data := range newEstimateTestData(t) {
+		exp := new(zetasketch.HLL)
+		if err := exp.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+
+		subject, err := zetasketch.NewHLLView(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got, exp := subject.NumValues(), exp.NumValues(); got != exp {
+			t.Errorf("%s NumValues: got %d, want %d", name, got, exp)
+		}
+		if got, exp := subject.ValueType(), exp.ValueType(); got != exp {
+			t.Errorf("%s ValueType: got %s, want %s", name, got, exp)
+		}
+		if got, exp := subject.Result(), exp.Result(); got != exp {
+			t.Errorf("%s Result: got %d, want %d", name, got, exp)
+		}
+		if got, exp := subject.Sketch().Estimate(), exp.Result(); got != exp {
+			t.Errorf("%s Sketch: got %d, want %d", name, got, exp)
+		}
+	}
+
+	if _, err := zetasketch.NewHLLView([]byte{0x08}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestHLL_MergeView(t *testing.T) {
+	other := zetasketch.NewHLL(nil)
+	for i := 800; i < 1_200; i++ {
+		other.Add(zetasketch.Uint64Value(uint64(i)))
+	}
+	data, err := other.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	view, err := zetasketch.NewHLLView(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject := newTestHLL()
+	if err := subject.MergeView(view); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := subject.NumValues(), int64(1_900); got != exp {
+		t.Errorf("NumValues: got %d, want %d", got, exp)
+	}
+	if got, exp := subject.Result(), int64(1_201); got != exp {
+		t.Errorf("Result: got %d, want %d", got, exp)
+	}
+
+	strs := zetasketch.NewHLL(nil)
+	strs.Add(zetasketch.StringValue("foo"))
+	if err := strs.MergeView(view); err == nil {
+		t.Error("expected error")
+	}
+}
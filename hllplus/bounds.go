@@ -0,0 +1,62 @@
+package hllplus
+
+import (
+	"fmt"
+	"math"
+)
+
+// Bounds is a cardinality estimate with its error bounds.
+type Bounds struct {
+	// Estimate is the cardinality estimate, as returned by Estimate.
+	Estimate int64
+	// StdError is the absolute standard error of the estimate.
+	StdError float64
+	// Lower and Upper bound the confidence interval of the estimate.
+	Lower, Upper int64
+}
+
+// RelativeError returns the relative standard error of the estimate.
+func (b Bounds) RelativeError() float64 {
+	if b.Estimate == 0 {
+		return 0
+	}
+	return b.StdError / float64(b.Estimate)
+}
+
+// EstimateBounds computes the cardinality estimate together with its standard
+// error and the bounds of the confidence interval at the given confidence
+// level, which must be between 0 and 1 (exclusive), e.g. 0.95.
+//
+// For sketches estimated via LinearCounting (all sparse sketches and normal
+// sketches with low cardinalities), the standard error is derived from the
+// variance of LinearCounting over the number of buckets (see Whang et al.
+// "A Linear-Time Probabilistic Counting Algorithm for Database Applications").
+// Otherwise, the relative standard error of HLL++ is 1.04 / sqrt(2^precision).
+func (s *HLL) EstimateBounds(confidence float64) (Bounds, error) {
+	if !(confidence > 0 && confidence < 1) {
+		return Bounds{}, fmt.Errorf("invalid confidence %v", confidence)
+	}
+
+	n, stdErr := s.estimateWithError()
+	b := Bounds{Estimate: n, StdError: stdErr}
+
+	// Two-sided interval, assuming normally distributed errors.
+	z := math.Sqrt2 * math.Erfinv(confidence)
+	d := z * b.StdError
+	b.Lower = max(0, int64(math.Floor(float64(n)-d)))
+	b.Upper = int64(math.Ceil(float64(n) + d))
+	return b, nil
+}
+
+// estimateWithError computes the cardinality estimate and its standard error.
+func (s *HLL) estimateWithError() (int64, float64) {
+	n, numBuckets := s.estimate()
+	if numBuckets != 0 {
+		m := float64(numBuckets)
+		t := float64(n) / m
+		return n, math.Sqrt(m * (math.Exp(t) - t - 1))
+	}
+
+	m := float64(uint64(1) << s.precision)
+	return n, float64(n) * 1.04 / math.Sqrt(m)
+}
@@ -0,0 +1,74 @@
+package hllplus_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/bsm/zetasketch/hllplus"
+)
+
+func TestHLL_EstimateBounds(t *testing.T) {
+	cases := []struct {
+		p, sp  uint8
+		n      int
+		sparse bool
+		relErr float64
+	}{
+		{15, 20, 0, true, 0},
+		{15, 20, 1_000, true, 0.0007},
+		{12, 17, 1_000, false, 0.0115},   // linear counting
+		{12, 17, 100_000, false, 0.0163}, // bias corrected
+		{15, 20, 100_000, false, 0.0058},
+	}
+	for _, tc := range cases {
+		rnd := rand.New(rand.NewSource(33))
+		subject, _ := hllplus.New(tc.p, tc.sp)
+		if !tc.sparse {
+			subject, _ = hllplus.NewNormal(tc.p)
+		}
+		for range tc.n {
+			subject.Add(rnd.Uint64())
+		}
+		if got := subject.IsSparse(); got != tc.sparse {
+			t.Errorf("p=%d sp=%d n=%d: IsSparse got %v, want %v", tc.p, tc.sp, tc.n, got, tc.sparse)
+		}
+
+		b, err := subject.EstimateBounds(0.99)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, exp := b.Estimate, subject.Estimate(); got != exp {
+			t.Errorf("p=%d sp=%d n=%d: Estimate got %d, want %d", tc.p, tc.sp, tc.n, got, exp)
+		}
+		if got := b.RelativeError(); math.Abs(got-tc.relErr) > 0.0001 {
+			t.Errorf("p=%d sp=%d n=%d: RelativeError got %.4f, want %.4f", tc.p, tc.sp, tc.n, got, tc.relErr)
+		}
+		if n := int64(tc.n); n < b.Lower || n > b.Upper {
+			t.Errorf("p=%d sp=%d n=%d: expected %d to be within [%d, %d]", tc.p, tc.sp, tc.n, n, b.Lower, b.Upper)
+		}
+	}
+}
+
+func TestHLL_EstimateBounds_confidence(t *testing.T) {
+	rnd := rand.New(rand.NewSource(33))
+	subject, _ := hllplus.NewNormal(12)
+	for range 100_000 {
+		subject.Add(rnd.Uint64())
+	}
+
+	b68, _ := subject.EstimateBounds(0.6827)
+	b95, _ := subject.EstimateBounds(0.95)
+	if got, exp := float64(b68.Upper-b68.Estimate), b68.StdError; math.Abs(got-exp) > 1 {
+		t.Errorf("got %v, want %v", got, exp)
+	}
+	if b95.Lower >= b68.Lower || b95.Upper <= b68.Upper {
+		t.Errorf("expected [%d, %d] to include [%d, %d]", b95.Lower, b95.Upper, b68.Lower, b68.Upper)
+	}
+
+	for _, c := range []float64{0, 1, -0.5, math.NaN()} {
+		if _, err := subject.EstimateBounds(c); err == nil {
+			t.Errorf("EstimateBounds(%v): expected error", c)
+		}
+	}
+}
@@ -17,16 +17,24 @@
 package hllplus
 
 import (
+	"cmp"
 	"math/bits"
+	"slices"
 	"sort"
 )
 
-/** The smallest and the largest precision for which thresholds and bias corrections are precisely defined. */
+/**
+ * The smallest and the largest precision for which thresholds and bias corrections are precisely
+ * defined. Data for precisions from minGenDataPrecision to maxGenDataPrecision is generated by
+ * gen_data.go and only used by EstimatorHLLPlusExtended.
+ */
 const (
 	minDataPrecision = 10
 	maxDataPrecision = 18
 )
 
+//go:generate go run gen_data.go
+
 // The number of neighbors that should be considered in the k-nearest neighbor algorithm to
 // determine the bias.
 const knnNumNeighbors = 6
@@ -681,12 +689,14 @@ func alpha(precision uint8) float64 {
 
 /**
  * Returns the bias correction for the given estimate and precision. These values have been
- * determined empirically as part of the HLL++ paper (https://goo.gl/pc916Z). In cases where a
- * bias has not been computed precisely for the given estimate, the bias is computed from the
- * weighted mean of its neighbors.
+ * determined empirically as part of the HLL++ paper (https://goo.gl/pc916Z), or by gen_data.go
+ * for precisions from minGenDataPrecision onwards if extended is set. In cases where a bias has
+ * not been computed precisely for the given estimate, the bias is computed from the weighted mean
+ * of its neighbors.
  */
-func estimateBias(estimate float64, precision uint8) float64 {
-	biases := closestBiases(estimate, precision)
+func estimateBias(estimate float64, precision uint8, extended bool) float64 {
+	var buf [2 * knnNumNeighbors]weightedBias
+	biases := closestBiases(buf[:0], estimate, precision, extended)
 	if len(biases) == 0 {
 		return 0
 	}
@@ -712,25 +722,22 @@ type weightedBias struct {
 	Distance float64
 }
 
-type weightedBiases []weightedBias
-
-func (p weightedBiases) Len() int           { return len(p) }
-func (p weightedBiases) Less(i, j int) bool { return p[i].Distance < p[j].Distance }
-func (p weightedBiases) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
-
 /**
  * Returns a list of the knnNumNeighbors closest biases and their distance to the estimate, sorted by increasing
- * distance.
+ * distance. The biases are appended to dst, which should have a capacity of 2 * knnNumNeighbors.
  */
-func closestBiases(estimate float64, precision uint8) weightedBiases {
-	// Return no bias correction when precision is out of defined bounds.
-	if precision < minDataPrecision || maxDataPrecision < precision {
+func closestBiases(dst []weightedBias, estimate float64, precision uint8, extended bool) []weightedBias {
+	var means, biases []float64
+	switch {
+	case minDataPrecision <= precision && precision <= maxDataPrecision:
+		means, biases = meanData[precision-minDataPrecision], biasData[precision-minDataPrecision]
+	case extended && minGenDataPrecision <= precision && precision <= maxGenDataPrecision:
+		means, biases = genMeanData[precision-minGenDataPrecision], genBiasData[precision-minGenDataPrecision]
+	default:
+		// Return no bias correction when precision is out of defined bounds.
 		return nil
 	}
 
-	biases := biasData[precision-minDataPrecision]
-	means := meanData[precision-minDataPrecision]
-
 	// Return no bias correction when estimate is out of bounds.
 	if estimate < means[0] || means[len(means)-1] < estimate {
 		return nil
@@ -745,7 +752,7 @@ func closestBiases(estimate float64, precision uint8) weightedBiases {
 		max = n
 	}
 
-	res := make(weightedBiases, 0, max-min)
+	res := dst[:0]
 	for i := min; i < max; i++ {
 		d := means[i] - estimate
 		res = append(res, weightedBias{
@@ -754,7 +761,11 @@ func closestBiases(estimate float64, precision uint8) weightedBiases {
 		})
 	}
 
-	sort.Sort(res)
+	// At equal distances, the bias with the lower mean comes first. This is the
+	// order sort.Sort produced, as it insertion sorts up to 12 elements.
+	slices.SortStableFunc(res, func(a, b weightedBias) int {
+		return cmp.Compare(a.Distance, b.Distance)
+	})
 	return res[:knnNumNeighbors]
 }
 
@@ -776,13 +787,17 @@ var linearCountingThresholds = []int64{
  * results than the HyperLogLog algorithm. Both algorithms can be used to determine the
  * cardinalities of multisets but, for low cardinalities (where the definition of "low" depends on
  * the precision), LinearCounting returns more accurate results than HyperLogLog. See the HLL++
- * paper (https://goo.gl/pc916Z) for details.
+ * paper (https://goo.gl/pc916Z) for details. If extended is set, the thresholds generated by
+ * gen_data.go are used for precisions from minGenDataPrecision onwards.
  *
  */
-func linearCountingThreshold(precision uint8) int64 {
+func linearCountingThreshold(precision uint8, extended bool) int64 {
 	if minDataPrecision <= precision && precision <= maxDataPrecision {
 		return linearCountingThresholds[precision-minDataPrecision]
 	}
+	if extended && minGenDataPrecision <= precision && precision <= maxGenDataPrecision {
+		return genLinearCountingThresholds[precision-minGenDataPrecision]
+	}
 
 	// Fall back to the threshold of 5m/2 as used in the original HLL paper for precisions where
 	// empirical thresholds have not yet been determined. See the HLL++ paper
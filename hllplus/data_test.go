@@ -1,7 +1,10 @@
 package hllplus_test
 
 import (
+	"fmt"
 	"math"
+	"math/rand"
+	"sort"
 	"testing"
 
 	"github.com/bsm/zetasketch/hllplus"
@@ -9,27 +12,142 @@ import (
 
 func TestEstimateBias(t *testing.T) {
 	cases := []struct {
-		e     float64
-		p     uint8
-		exp   float64
-		delta float64
+		e        float64
+		p        uint8
+		extended bool
+		exp      float64
+		delta    float64
 	}{
-		{0, 15, 0.0, 0},
-		{1, 15, 0.0, 0},
-		{10_000, 15, 0.0, 0},
-		{100_000, 15, 888.1, 0.1},
-		{200_000, 15, 0.0, 0},
-
-		{50_000, 13, 0.0, 0},
-		{50_000, 14, 449.7, 0.1},
-		{50_000, 15, 7820.2, 0.1},
-		{50_000, 16, 44513.2, 0.1},
-		{50_000, 17, 0.0, 0},
+		{0, 15, false, 0.0, 0},
+		{1, 15, false, 0.0, 0},
+		{10_000, 15, false, 0.0, 0},
+		{100_000, 15, false, 888.1, 0.1},
+		{200_000, 15, false, 0.0, 0},
+
+		{50_000, 13, false, 0.0, 0},
+		{50_000, 14, false, 449.7, 0.1},
+		{50_000, 15, false, 7820.2, 0.1},
+		{50_000, 16, false, 44513.2, 0.1},
+		{50_000, 17, false, 0.0, 0},
+
+		// generated data is only used if extended
+		{50_000, 15, true, 7820.2, 0.1},
+		{1_000_000, 19, false, 0.0, 0},
+		{50_000_000, 24, false, 0.0, 0},
+
+		{1_000_000, 19, true, 76270.7, 0.1},
+		{1_000_000, 20, true, 541496.7, 0.1},
+		{5_000_000, 20, true, 2170.1, 0.1},
+		{10_000_000, 22, true, 324120.7, 0.1},
+		{50_000_000, 24, true, 561234.9, 0.1},
+		{100_000_000, 24, true, 0.0, 0},
 	}
 	for _, tc := range cases {
-		got := hllplus.EstimateBias(tc.e, tc.p)
+		got := hllplus.EstimateBias(tc.e, tc.p, tc.extended)
 		if math.Abs(got-tc.exp) > tc.delta {
-			t.Errorf("EstimateBias(%v, %d) = %v, want %v (±%v)", tc.e, tc.p, got, tc.exp, tc.delta)
+			t.Errorf("EstimateBias(%v, %d, %v) = %v, want %v (±%v)", tc.e, tc.p, tc.extended, got, tc.exp, tc.delta)
+		}
+	}
+}
+
+// TestEstimateBias_ties checks estimates which are equidistant to two means,
+// where the order of the neighbors decides which of them is used. The result
+// must be the same as with the original sort.Sort based selection.
+func TestEstimateBias_ties(t *testing.T) {
+	for p := uint8(hllplus.MinPrecision); p <= hllplus.MaxPrecision; p++ {
+		means, biases := hllplus.BiasData(p)
+		for i := hllplus.KNNNumNeighbors; i < len(means); i++ {
+			e := (means[i-hllplus.KNNNumNeighbors] + means[i]) / 2
+			if got, exp := hllplus.EstimateBias(e, p, true), sortedEstimateBias(means, biases, e); got != exp {
+				t.Errorf("EstimateBias(%v, %d) = %v, want %v", e, p, got, exp)
+			}
 		}
 	}
 }
+
+// sortedEstimateBias is the original implementation of estimateBias.
+func sortedEstimateBias(means, biases []float64, e float64) float64 {
+	index := sort.SearchFloat64s(means, e)
+	lo, hi := max(0, index-hllplus.KNNNumNeighbors), min(len(means), index+hllplus.KNNNumNeighbors)
+
+	var res weightedBiases
+	for i := lo; i < hi; i++ {
+		d := means[i] - e
+		res = append(res, weightedBias{Bias: biases[i], Distance: d * d})
+	}
+	sort.Sort(res)
+	res = res[:hllplus.KNNNumNeighbors]
+
+	if res[0].Distance == 0 {
+		return res[0].Bias
+	}
+	sum, totalWeight := 0.0, 0.0
+	for _, b := range res {
+		totalWeight += 1.0 / b.Distance
+		sum += b.Bias / b.Distance
+	}
+	return sum / totalWeight
+}
+
+type weightedBias struct{ Bias, Distance float64 }
+
+type weightedBiases []weightedBias
+
+func (p weightedBiases) Len() int           { return len(p) }
+func (p weightedBiases) Less(i, j int) bool { return p[i].Distance < p[j].Distance }
+func (p weightedBiases) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// TestHLL_Estimate_generatedData checks the generated bias corrections of
+// EstimatorHLLPlusExtended against an independent simulation, which uses a
+// different random source than gen_data.go. Around the cardinalities where the corrections apply, the mean
+// relative error over all runs must be within 3 standard errors of zero and
+// the root mean squared error must be close to the theoretical 1.04/sqrt(m).
+func TestHLL_Estimate_generatedData(t *testing.T) {
+	const runs = 8
+
+	for _, p := range []uint8{19, 20} {
+		t.Run(fmt.Sprintf("p=%d", p), func(t *testing.T) {
+			if testing.Short() && p > 19 {
+				t.Skip("skipping in short mode")
+			}
+
+			m := 1 << p
+			stdErr := 1.04 / math.Sqrt(float64(m))
+			checkpoints := []int{m / 2, m, 2 * m, 4 * m}
+
+			var sumErr, sumSqErr [4]float64
+			hashes := make([]uint64, 1<<16)
+			for run := range runs {
+				rnd := rand.New(rand.NewSource(int64(p)<<32 | int64(run)))
+				subject, _ := hllplus.NewNormal(p)
+				subject.SetEstimator(hllplus.EstimatorHLLPlusExtended)
+
+				n := 0
+				for i, cp := range checkpoints {
+					for n < cp {
+						batch := hashes[:min(len(hashes), cp-n)]
+						for j := range batch {
+							batch[j] = rnd.Uint64()
+						}
+						subject.AddBatch(batch)
+						n += len(batch)
+					}
+
+					relErr := float64(subject.Estimate()-int64(n)) / float64(n)
+					sumErr[i] += relErr
+					sumSqErr[i] += relErr * relErr
+				}
+			}
+
+			for i, n := range checkpoints {
+				bias := sumErr[i] / runs
+				if limit := 3 * stdErr / math.Sqrt(runs); math.Abs(bias) > limit {
+					t.Errorf("n=%d: mean relative error %.5f exceeds %.5f", n, bias, limit)
+				}
+				if rmse, limit := math.Sqrt(sumSqErr[i]/runs), 1.5*stdErr; rmse > limit {
+					t.Errorf("n=%d: root mean squared error %.5f exceeds %.5f", n, rmse, limit)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package hllplus_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/bsm/zetasketch/hllplus"
+)
+
+func TestEstimator_String(t *testing.T) {
+	cases := []struct {
+		e   hllplus.Estimator
+		exp string
+	}{
+		{hllplus.EstimatorHLLPlus, "HLLPlus"},
+		{hllplus.EstimatorImproved, "Improved"},
+		{hllplus.EstimatorMaxLikelihood, "MaxLikelihood"},
+		{hllplus.EstimatorHLLPlusExtended, "HLLPlusExtended"},
+		{hllplus.Estimator(9), "Estimator(9)"},
+	}
+	for _, tc := range cases {
+		if got := tc.e.String(); got != tc.exp {
+			t.Errorf("got %q, want %q", got, tc.exp)
+		}
+	}
+}
+
+func TestHLL_SetEstimator(t *testing.T) {
+	subject, _ := hllplus.New(12, 17)
+	if got, want := subject.Estimator(), hllplus.EstimatorHLLPlus; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	rnd := rand.New(rand.NewSource(33))
+	for range 800 {
+		subject.Add(rnd.Uint64())
+	}
+
+	// sparse sketches are always estimated via LinearCounting
+	exp := subject.Estimate()
+	for _, e := range []hllplus.Estimator{hllplus.EstimatorImproved, hllplus.EstimatorMaxLikelihood} {
+		subject.SetEstimator(e)
+		if got := subject.Estimate(); got != exp {
+			t.Errorf("%s: got %d, want %d", e, got, exp)
+		}
+		if got := subject.Clone().Estimator(); got != e {
+			t.Errorf("clone: got %s, want %s", got, e)
+		}
+	}
+}
+
+func TestHLL_SetEstimator_edgeCases(t *testing.T) {
+	for _, e := range []hllplus.Estimator{hllplus.EstimatorImproved, hllplus.EstimatorMaxLikelihood} {
+		t.Run(e.String(), func(t *testing.T) {
+			subject, _ := hllplus.NewNormal(10)
+			subject.SetEstimator(e)
+			if got := subject.Estimate(); got != 0 {
+				t.Errorf("empty: got %d, want 0", got)
+			}
+
+			// a single value with the maximum rho
+			subject.Add(1)
+			if got := subject.Estimate(); got != 1 {
+				t.Errorf("single: got %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestHLL_SetEstimator_extended(t *testing.T) {
+	for _, tc := range []struct {
+		p     uint8
+		n     int
+		equal bool
+	}{
+		{15, 50_000, true},     // bias correction of the HLL++ paper
+		{15, 200_000, true},    // no bias correction
+		{19, 1_000_000, false}, // generated bias correction
+	} {
+		rnd := rand.New(rand.NewSource(33))
+		subject, _ := hllplus.NewNormal(tc.p)
+		for range tc.n {
+			subject.Add(rnd.Uint64())
+		}
+
+		exp := subject.Estimate()
+		subject.SetEstimator(hllplus.EstimatorHLLPlusExtended)
+		if got := subject.Estimate(); (got == exp) != tc.equal {
+			t.Errorf("p=%d n=%d: got %d, default %d", tc.p, tc.n, got, exp)
+		}
+	}
+}
+
+// TestHLL_estimatorAccuracy compares the relative error of the estimators
+// across all precisions and a range of cardinalities. The error must be
+// within 5 standard errors of the theoretical 1.04/sqrt(m). To limit the
+// runtime, cardinalities beyond m are only checked up to precision 20.
+func TestHLL_estimatorAccuracy(t *testing.T) {
+	estimators := []hllplus.Estimator{
+		hllplus.EstimatorHLLPlus,
+		hllplus.EstimatorImproved,
+		hllplus.EstimatorMaxLikelihood,
+		hllplus.EstimatorHLLPlusExtended,
+	}
+
+	for p := uint8(hllplus.MinPrecision); p <= hllplus.MaxPrecision; p++ {
+		t.Run(fmt.Sprintf("p=%d", p), func(t *testing.T) {
+			if testing.Short() && p > 20 {
+				t.Skip("skipping in short mode")
+			}
+			t.Parallel()
+
+			m := 1 << p
+			maxErr := 5 * 1.04 / math.Sqrt(float64(m))
+			checkpoints := []int{m / 10, m / 2, m, 2 * m, 3 * m, 5 * m}
+			if p > 20 {
+				checkpoints = checkpoints[:3]
+			}
+
+			rnd := rand.New(rand.NewSource(int64(p)))
+			subject, _ := hllplus.NewNormal(p)
+			hashes := make([]uint64, 0, 1<<16)
+			n := 0
+			for _, cp := range checkpoints {
+				for n < cp {
+					hashes = hashes[:min(cap(hashes), cp-n)]
+					for i := range hashes {
+						hashes[i] = rnd.Uint64()
+					}
+					subject.AddBatch(hashes)
+					n += len(hashes)
+				}
+
+				for _, e := range estimators {
+					if e == hllplus.EstimatorHLLPlus && p > 18 {
+						continue // no empirical bias data beyond p=18
+					}
+
+					subject.SetEstimator(e)
+					got := subject.Estimate()
+					if relErr := float64(got-int64(n)) / float64(n); math.Abs(relErr) > maxErr {
+						t.Errorf("%s n=%d: got %d, relative error %.4f exceeds %.4f", e, n, got, relErr, maxErr)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkHLL_Estimate(b *testing.B) {
+	for _, e := range []hllplus.Estimator{
+		hllplus.EstimatorHLLPlus,
+		hllplus.EstimatorImproved,
+		hllplus.EstimatorMaxLikelihood,
+		hllplus.EstimatorHLLPlusExtended,
+	} {
+		subject, _ := hllplus.NewNormal(15)
+		subject.AddBatch(benchHashes(100_000))
+		subject.SetEstimator(e)
+
+		b.Run(e.String(), func(b *testing.B) {
+			for b.Loop() {
+				_ = subject.Estimate()
+			}
+		})
+	}
+}
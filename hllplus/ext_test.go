@@ -1,10 +1,30 @@
 package hllplus
 
 // EstimateBias test export.
-func EstimateBias(e float64, p uint8) float64 {
-	return estimateBias(e, p)
+func EstimateBias(e float64, p uint8, extended bool) float64 {
+	return estimateBias(e, p, extended)
 }
 
+// BiasData test export.
+func BiasData(p uint8) (means, biases []float64) {
+	if p < minGenDataPrecision {
+		return meanData[p-minDataPrecision], biasData[p-minDataPrecision]
+	}
+	return genMeanData[p-minGenDataPrecision], genBiasData[p-minGenDataPrecision]
+}
+
+const KNNNumNeighbors = knnNumNeighbors
+
+// Register kernel test exports.
+var (
+	MaxRegisters              = maxRegisters
+	MaxRegistersGeneric       = maxRegistersGeneric
+	CountZeroRegisters        = countZeroRegisters
+	CountZeroRegistersGeneric = countZeroRegistersGeneric
+	HarmonicSum               = harmonicSum
+	HarmonicSumScalar         = harmonicSumScalar
+)
+
 func NewNormal(precision uint8) (*HLL, error) {
 	pp := min(precision+5, MaxSparsePrecision)
 
@@ -19,3 +39,7 @@ func NewNormal(precision uint8) (*HLL, error) {
 func (s *HLL) IsSparse() bool {
 	return s.sparse != nil
 }
+
+func (s *HLL) NumBuffered() int {
+	return s.sparse.buffer.Len()
+}
@@ -0,0 +1,198 @@
+//go:build ignore
+
+// This program generates data_gen.go, which contains the empirical bias
+// corrections and LinearCounting thresholds for the precisions that are not
+// covered by the data of the HLL++ paper. Run it with:
+//
+//	go run gen_data.go [-runs N] [-seed S] [-o data_gen.go]
+//
+// For each precision, it simulates -runs sketches on uniform random hashes and
+// records the raw estimate at 201 equidistant cardinalities between 0 and 5m,
+// where m is the number of registers. The mean of the raw estimates and their
+// bias (mean - cardinality) form the lookup table for estimateBias. The
+// LinearCounting threshold is the smallest recorded cardinality from which on
+// the mean squared error of LinearCounting exceeds the variance of the
+// bias-corrected raw estimate, approximated by the variance of the raw
+// estimate divided by the squared slope of the means. The output is
+// deterministic for a given seed and number of runs.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"math"
+	"math/bits"
+	"math/rand/v2"
+	"os"
+	"strconv"
+)
+
+const (
+	minPrecision = 19
+	maxPrecision = 24
+
+	// The number of cardinalities per precision, in steps of m/40.
+	numPoints = 201
+	stepsPerM = 40
+)
+
+func main() {
+	runs := flag.Int("runs", 1000, "number of simulated sketches per precision")
+	seed := flag.Uint64("seed", 1, "random seed")
+	out := flag.String("o", "data_gen.go", "output file")
+	flag.Parse()
+
+	var tables []table
+	for p := uint8(minPrecision); p <= maxPrecision; p++ {
+		log.Printf("simulating precision %d", p)
+		tables = append(tables, simulate(p, *runs, *seed))
+	}
+
+	src, err := render(tables, *runs, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type table struct {
+	precision uint8
+	means     []float64
+	biases    []float64
+	threshold int64
+}
+
+// simulate computes the table for precision p.
+func simulate(p uint8, runs int, seed uint64) table {
+	m := 1 << p
+	q := 64 - p
+	alpha := 0.7213 / (1 + 1.079/float64(m))
+
+	var (
+		sumRaw   = make([]float64, numPoints)
+		sumRawSq = make([]float64, numPoints)
+		sumLCErr = make([]float64, numPoints)
+	)
+
+	registers := make([]byte, m)
+	for run := range runs {
+		rnd := rand.New(rand.NewPCG(seed, uint64(p)<<32|uint64(run)))
+		clear(registers)
+
+		// The histogram of register values, which is sufficient to compute
+		// both estimates.
+		var counts [66]int
+		counts[0] = m
+
+		n := 0
+		for i := range numPoints {
+			for target := i * m / stepsPerM; n < target; n++ {
+				hash := rnd.Uint64()
+				pos := hash >> q
+				rho := q + 1
+				if w := hash << p; w != 0 {
+					rho = uint8(bits.LeadingZeros64(w)) + 1
+				}
+				if old := registers[pos]; rho > old {
+					registers[pos] = rho
+					counts[old]--
+					counts[rho]++
+				}
+			}
+
+			sum := 0.0
+			for rho, c := range counts {
+				sum += math.Ldexp(float64(c), -rho)
+			}
+			raw := alpha * float64(m) * float64(m) / sum
+			sumRaw[i] += raw
+			sumRawSq[i] += raw * raw
+
+			lcErr := math.Inf(1)
+			if counts[0] != 0 {
+				lcErr = float64(m)*math.Log(float64(m)/float64(counts[0])) - float64(n)
+			}
+			sumLCErr[i] += lcErr * lcErr
+		}
+	}
+
+	t := table{
+		precision: p,
+		means:     make([]float64, numPoints),
+		biases:    make([]float64, numPoints),
+	}
+	step := float64(m / stepsPerM)
+	for i := range numPoints {
+		n := float64(i * m / stepsPerM)
+		mean := sumRaw[i] / float64(runs)
+		t.means[i] = round(mean)
+		t.biases[i] = round(mean - n)
+
+		if i != 0 && t.means[i] <= t.means[i-1] {
+			log.Fatalf("precision %d: means are not increasing at %d, increase -runs", p, i)
+		}
+	}
+
+	// The error of the bias-corrected estimate is the deviation of the raw
+	// estimate, scaled by the slope of the means.
+	t.threshold = int64(numPoints-1) * int64(m) / stepsPerM
+	for i := numPoints - 2; i > 0; i-- {
+		mean := sumRaw[i] / float64(runs)
+		variance := sumRawSq[i]/float64(runs) - mean*mean
+		slope := (sumRaw[i+1] - sumRaw[i-1]) / float64(runs) / (2 * step)
+		if sumLCErr[i]/float64(runs) <= variance/(slope*slope) {
+			break
+		}
+		t.threshold = int64(float64(i) * step)
+	}
+	return t
+}
+
+// round rounds x to three decimals.
+func round(x float64) float64 {
+	return math.Round(x*1000) / 1000
+}
+
+func render(tables []table, runs int, seed uint64) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by \"go run gen_data.go -runs %d -seed %d\"; DO NOT EDIT.\n\n", runs, seed)
+	fmt.Fprintf(&b, "package hllplus\n\n")
+	fmt.Fprintf(&b, "// The smallest and the largest precision covered by the generated data.\n")
+	fmt.Fprintf(&b, "const (\nminGenDataPrecision = %d\nmaxGenDataPrecision = %d\n)\n\n", minPrecision, maxPrecision)
+
+	fmt.Fprintf(&b, "// Means of the raw estimates, see meanData.\n")
+	renderFloats(&b, "genMeanData", tables, func(t table) []float64 { return t.means })
+	fmt.Fprintf(&b, "// Biases of the raw estimates, see biasData.\n")
+	renderFloats(&b, "genBiasData", tables, func(t table) []float64 { return t.biases })
+
+	fmt.Fprintf(&b, "// Thresholds for when to apply LinearCounting, see linearCountingThresholds.\n")
+	fmt.Fprintf(&b, "var genLinearCountingThresholds = []int64{\n")
+	for _, t := range tables {
+		fmt.Fprintf(&b, "%d, // precision %d\n", t.threshold, t.precision)
+	}
+	fmt.Fprintf(&b, "}\n")
+
+	return format.Source(b.Bytes())
+}
+
+func renderFloats(b *bytes.Buffer, name string, tables []table, values func(table) []float64) {
+	fmt.Fprintf(b, "var %s = [][]float64{\n", name)
+	for _, t := range tables {
+		fmt.Fprintf(b, "// prec %d\n{\n", t.precision)
+		for i, v := range values(t) {
+			b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+			if i%5 == 4 {
+				b.WriteString(",\n")
+			} else {
+				b.WriteString(", ")
+			}
+		}
+		fmt.Fprintf(b, "\n},\n")
+	}
+	fmt.Fprintf(b, "}\n\n")
+}
@@ -21,6 +21,7 @@ type HLL struct {
 
 	precision       uint8
 	sparsePrecision uint8
+	estimator       Estimator
 }
 
 // New inits a new sketch.
@@ -54,6 +55,8 @@ func NewFromProto(msg *pb.HyperLogLogPlusUniqueStateProto) (*HLL, error) {
 
 	if len(msg.SparseData) > 0 {
 		h.sparse = newSparseState(precision, sparsePrecision, msg.SparseData)
+	} else if err := validateNormal(msg.Data, precision); err != nil {
+		return nil, err
 	} else {
 		h.normal = msg.Data
 	}
@@ -71,6 +74,17 @@ func (s *HLL) SparsePrecision() uint8 {
 	return s.sparsePrecision
 }
 
+// Estimator returns the estimator used for normal registers.
+func (s *HLL) Estimator() Estimator {
+	return s.estimator
+}
+
+// SetEstimator sets the estimator used for normal registers. The estimator is
+// not part of the serialized state.
+func (s *HLL) SetEstimator(e Estimator) {
+	s.estimator = e
+}
+
 // Add adds the uniform hash value to the representation.
 func (s *HLL) Add(hash uint64) {
 	if s.sparse != nil {
@@ -87,46 +101,52 @@ func (s *HLL) Add(hash uint64) {
 	}
 }
 
-// Merge merges other into s.
-func (s *HLL) Merge(other *HLL) {
-	// Skip if there is nothing to merge.
-	if len(other.normal) == 0 && other.sparse == nil {
-		return
-	}
-
-	// FIXME: allow sparse merge
-	if s.sparse != nil {
-		s.normalize()
+// AddBatch adds multiple uniform hash values to the representation. The
+// result is the same as calling Add for each hash, but checks for the
+// representation are performed once per flush of the sparse buffer instead of
+// once per hash.
+func (s *HLL) AddBatch(hashes []uint64) {
+	for len(hashes) != 0 && s.sparse != nil {
+		if hashes = s.sparse.AddBatch(hashes); s.sparse.OverMax() {
+			s.normalize()
+		}
 	}
-	if other.sparse != nil {
-		other = other.Clone()
-		other.normalize()
+	if len(hashes) == 0 {
+		return
 	}
 
-	// Make sure receiver is allocated.
 	s.ensureNormal()
+	normal, precision := s.normal, s.precision
+	for _, hash := range hashes {
+		pos, rho := computePosRhoW(hash, precision)
+		if rho > normal[pos] {
+			normal[pos] = rho
+		}
+	}
+}
 
-	// If other precision is higher.
-	if s.precision < other.precision {
-		other.downgradeEach(s.precision, func(pos uint32, rhoW uint8) {
-			if s.normal[pos] < rhoW {
-				s.normal[pos] = rhoW
-			}
-		})
+// Merge merges other into s.
+func (s *HLL) Merge(other *HLL) {
+	// Skip if there is nothing to merge.
+	if len(other.normal) == 0 && other.sparse == nil {
 		return
 	}
 
-	// If other precision is lower, downgrade.
-	if s.precision > other.precision {
-		_ = s.Downgrade(other.precision, other.sparsePrecision)
-	}
+	// Reduce receiver to the lowest common precisions.
+	_ = s.Downgrade(other.precision, other.sparsePrecision)
 
-	// Use largest rhoW.
-	for i, rho := range other.normal {
-		if s.normal[i] < rho {
-			s.normal[i] = rho
+	// Merge sparse representations directly, the result is only normalized
+	// if it grows too large.
+	if s.sparse != nil && other.sparse != nil {
+		if s.sparse.Merge(other.sparse); s.sparse.OverMax() {
+			s.normalize()
 		}
+		return
 	}
+
+	s.normalize()
+	s.ensureNormal()
+	s.mergeNormal(other)
 }
 
 // Clone creates a copy of the sketch.
@@ -134,6 +154,7 @@ func (s *HLL) Clone() *HLL {
 	clone := &HLL{
 		precision:       s.precision,
 		sparsePrecision: s.sparsePrecision,
+		estimator:       s.estimator,
 		sparse:          s.sparse.Clone(),
 	}
 	if len(s.normal) != 0 {
@@ -143,53 +164,67 @@ func (s *HLL) Clone() *HLL {
 	return clone
 }
 
-// Estimate computes the cardinality estimate according to the algorithm in Figure 6 of the HLL++ paper
-// (https://goo.gl/pc916Z).
+// Estimate computes the cardinality estimate. By default, it follows the algorithm in Figure 6 of
+// the HLL++ paper (https://goo.gl/pc916Z), see SetEstimator for alternatives.
 func (s *HLL) Estimate() int64 {
-	if s.sparse != nil {
-		s.sparse.Flush()
-		return s.sparse.Estimate()
-	}
+	n, _ := s.estimate()
+	return n
+}
 
-	if len(s.normal) == 0 {
-		return 0
+// estimate computes the cardinality estimate. If LinearCounting was applied, it
+// additionally returns the number of buckets that were counted, 0 otherwise.
+// It does not modify s, so sketches can be estimated concurrently.
+func (s *HLL) estimate() (int64, int) {
+	if s.sparse != nil {
+		return s.sparse.Estimate(), 1 << s.sparsePrecision
 	}
 
-	// Compute the summation component of the harmonic mean for the HLL++ algorithm while also
-	// keeping track of the number of zeros in case we need to apply LinearCounting instead.
-	numZeros := 0
-	sum := 0.0
+	return s.estimator.estimate(s.normal, s.precision)
+}
 
-	for _, c := range s.normal {
-		if c == 0 {
-			numZeros++
-		}
+// EstimateData computes the cardinality estimate of a serialized sketch from
+// its normal or sparse data, as stored in HyperLogLogPlusUniqueStateProto,
+// without copying or decoding them. The sparse data takes precedence. The
+// estimate is always computed with EstimatorHLLPlus, see View for a
+// configurable alternative.
+func EstimateData(precision, sparsePrecision uint8, data, sparseData []byte) (int64, error) {
+	v, err := makeView(precision, sparsePrecision, data, sparseData)
+	if err != nil {
+		return 0, err
+	}
+	return v.Estimate(), nil
+}
 
-		// Compute sum += math.pow(2, -v) without actually performing a floating point exponent
-		// computation (which is expensive). v can be at most 64 - precision + 1 and the minimum
-		// precision is larger than 2 (see MINIMUM_PRECISION), so this left shift can not overflow.
-		x := 1 << c
-		sum += 1.0 / float64(x)
+// estimateNormal computes the cardinality estimate from normal registers. If
+// LinearCounting was applied, it additionally returns the number of buckets
+// that were counted, 0 otherwise. If extended is set, the generated data is
+// used for precisions beyond those of the HLL++ paper.
+func estimateNormal(normal []byte, precision uint8, extended bool) (int64, int) {
+	if len(normal) == 0 {
+		return 0, 0
 	}
 
 	// Return the LinearCount for small cardinalities where, as explained in the HLL++ paper
 	// (https://goo.gl/pc916Z), the results with LinearCount tend to be more accurate than with HLL.
-	x := 1 << s.precision
+	x := 1 << precision
 	m := float64(x)
-	if numZeros != 0 {
+	if numZeros := countZeroRegisters(normal); numZeros != 0 {
 		n := int64(m*math.Log(m/float64(numZeros)) + 0.5)
-		if n <= linearCountingThreshold(s.precision) {
-			return n
+		if n <= linearCountingThreshold(precision, extended) {
+			return n, x
 		}
 	}
 
+	// Compute the summation component of the harmonic mean for the HLL++ algorithm.
+	sum := harmonicSum(normal)
+
 	// The "raw" estimate, designated by E in the HLL++ paper (https://goo.gl/pc916Z).
-	raw := alpha(s.precision) * m * m / sum
+	raw := alpha(precision) * m * m / sum
 
 	// Perform bias correction on small estimates. HyperLogLogPlusPlusData only contains bias
 	// estimates for small cardinalities and returns 0 for anything else, so the "E < 5m" guard from
 	// the HLL++ paper (https://goo.gl/pc916Z) is superfluous here.
-	return int64(raw - estimateBias(raw, s.precision) + 0.5)
+	return int64(raw - estimateBias(raw, precision, extended) + 0.5), 0
 }
 
 // Downgrade tries to reduce the precision of the sketch.
@@ -199,23 +234,29 @@ func (s *HLL) Downgrade(precision, sparsePrecision uint8) error {
 		return err
 	}
 
-	// TODO: downgrade sparse as well (and don't forget a switch between normal and sparse)
-
-	if s.precision > precision {
-		if len(s.normal) != 0 {
-			normal := make([]byte, 1<<precision)
-			s.downgradeEach(precision, func(pos uint32, rhoW uint8) {
-				if normal[pos] < rhoW {
-					normal[pos] = rhoW
-				}
-			})
-			s.normal = normal
-		}
-		s.precision = precision
+	precision = min(precision, s.precision)
+	sparsePrecision = min(sparsePrecision, s.sparsePrecision)
+	if precision == s.precision && sparsePrecision == s.sparsePrecision {
+		return nil
 	}
 
-	if s.sparsePrecision > sparsePrecision {
-		s.sparsePrecision = sparsePrecision
+	if s.sparse != nil {
+		s.sparse = s.sparse.Downgrade(precision, sparsePrecision)
+	} else if len(s.normal) != 0 && precision < s.precision {
+		normal := make([]byte, 1<<precision)
+		s.downgradeEach(precision, func(pos uint32, rhoW uint8) {
+			if normal[pos] < rhoW {
+				normal[pos] = rhoW
+			}
+		})
+		s.normal = normal
+	}
+	s.precision = precision
+	s.sparsePrecision = sparsePrecision
+
+	// Switch to normal representation if the re-encoded sparse data is too large.
+	if s.sparse != nil && s.sparse.OverMax() {
+		s.normalize()
 	}
 	return nil
 }
@@ -261,6 +302,15 @@ func validate(precision, sparsePrecision uint8) error {
 	return nil
 }
 
+// validateNormal checks that normal data has the number of registers, i.e. one
+// byte per bucket, of the given precision, unless it is empty.
+func validateNormal(data []byte, precision uint8) error {
+	if n := len(data); n != 0 && n != 1<<precision {
+		return fmt.Errorf("invalid normal data length %d: must be %d for precision %d", n, 1<<precision, precision)
+	}
+	return nil
+}
+
 // Proto builds a BigQuery-compatible protobuf message, representing HLL aggregator state.
 func (s *HLL) Proto() *pb.HyperLogLogPlusUniqueStateProto {
 	// both precisions must always be marshalled:
@@ -1,6 +1,7 @@
 package hllplus_test
 
 import (
+	"bytes"
 	"fmt"
 	"math/rand"
 	"testing"
@@ -45,7 +46,7 @@ func TestHLL_estimateSparse800(t *testing.T) {
 		p   int
 		exp int64
 	}{
-		{16, 795},
+		{16, 796},
 		{17, 798},
 		{18, 799},
 		{19, 799},
@@ -114,7 +115,7 @@ func TestHLL_estimateSparse200k(t *testing.T) {
 		p   int
 		exp int64
 	}{
-		{24, 200039},
+		{24, 200040},
 		{25, 200048},
 	}
 	for _, tc := range cases {
@@ -182,8 +183,8 @@ func TestHLL_estimateSparse100k2x50k(t *testing.T) {
 		p   int
 		exp int64
 	}{
-		{23, 149969},
-		{24, 149998},
+		{23, 149970},
+		{24, 149999},
 		{25, 150012},
 	}
 	for _, tc := range cases {
@@ -225,6 +226,22 @@ func TestHLL_estimateSparseRepetitive(t *testing.T) {
 	}
 }
 
+func TestHLL_estimateSparse_flushSmaller(t *testing.T) {
+	// Flushing buffered values which are all smaller than a stored value must
+	// retain the stored value.
+	subject, _ := hllplus.New(10, 15)
+	subject.Add(0xf000_0000_0000_0000)
+	if got := subject.Estimate(); got != 1 {
+		t.Errorf("got %d, want 1", got)
+	}
+
+	subject.Add(0x1000_0000_0000_0000)
+	subject.Add(0x2000_0000_0000_0000)
+	if got := subject.Estimate(); got != 3 {
+		t.Errorf("got %d, want 3", got)
+	}
+}
+
 func TestHLL_normalize(t *testing.T) {
 	rnd := rand.New(rand.NewSource(33))
 	subject, _ := hllplus.New(12, 17)
@@ -234,8 +251,8 @@ func TestHLL_normalize(t *testing.T) {
 	if !subject.IsSparse() {
 		t.Error("expected sparse representation")
 	}
-	if got := subject.Estimate(); got != 3_083 {
-		t.Errorf("got %d, want 3083", got)
+	if got := subject.Estimate(); got != 3_085 {
+		t.Errorf("got %d, want 3085", got)
 	}
 
 	subject.Add(rnd.Uint64())
@@ -289,6 +306,65 @@ func TestHLL_downgrade(t *testing.T) {
 	}
 }
 
+func TestHLL_downgradeSparse(t *testing.T) {
+	rnd := rand.New(rand.NewSource(33))
+	subject, _ := hllplus.New(15, 20)
+	exp, _ := hllplus.New(13, 17)
+
+	for range 1_000 {
+		n := rnd.Uint64()
+		subject.Add(n)
+		exp.Add(n)
+	}
+
+	if err := subject.Downgrade(13, 17); err != nil {
+		t.Fatal(err)
+	}
+	if !subject.IsSparse() {
+		t.Error("expected sparse representation")
+	}
+	if got := subject.Precision(); got != 13 {
+		t.Errorf("Precision: got %d, want 13", got)
+	}
+	if got := subject.SparsePrecision(); got != 17 {
+		t.Errorf("SparsePrecision: got %d, want 17", got)
+	}
+	if got, want := subject.Estimate(), exp.Estimate(); got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+	if got, want := subject.Proto().GetSparseData(), exp.Proto().GetSparseData(); !bytes.Equal(got, want) {
+		t.Errorf("got sparse data %x, want %x", got, want)
+	}
+}
+
+func TestHLL_downgradeSparseToNormal(t *testing.T) {
+	rnd := rand.New(rand.NewSource(33))
+	subject, _ := hllplus.New(15, 20)
+	exp, _ := hllplus.New(10, 15)
+
+	for range 2_000 {
+		n := rnd.Uint64()
+		subject.Add(n)
+		exp.Add(n)
+	}
+	if !subject.IsSparse() {
+		t.Fatal("expected sparse representation")
+	}
+
+	if err := subject.Downgrade(10, 15); err != nil {
+		t.Fatal(err)
+	}
+	if subject.IsSparse() {
+		t.Error("expected normal representation")
+	}
+	if got, want := subject.Estimate(), exp.Estimate(); got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+	if got, want := subject.Proto().GetData(), exp.Proto().GetData(); !bytes.Equal(got, want) {
+		t.Error("expected normal data to match")
+	}
+}
+
 // newMergeFixture builds three sketches sharing 50k values and adds 50k distinct
 // values to each, matching the original merge spec's BeforeEach.
 func newMergeFixture(t *testing.T) (s1, s2, s3 *hllplus.HLL) {
@@ -377,6 +453,104 @@ func TestHLL_merge_emptyTarget(t *testing.T) {
 	}
 }
 
+func TestHLL_merge_sparse(t *testing.T) {
+	rnd := rand.New(rand.NewSource(33))
+	s1, _ := hllplus.New(15, 20)
+	s2, _ := hllplus.New(15, 20)
+	exp, _ := hllplus.New(15, 20)
+
+	for range 50 {
+		n := rnd.Uint64()
+		s1.Add(n)
+		s2.Add(n)
+		exp.Add(n)
+	}
+	for range 50 {
+		n1, n2 := rnd.Uint64(), rnd.Uint64()
+		s1.Add(n1)
+		s2.Add(n2)
+		exp.Add(n1)
+		exp.Add(n2)
+	}
+
+	s1.Merge(s2)
+	if !s1.IsSparse() {
+		t.Error("expected sparse representation")
+	}
+	if got := s1.Estimate(); got != 150 {
+		t.Errorf("got %d, want 150", got)
+	}
+	if got, want := s1.Proto().GetSparseData(), exp.Proto().GetSparseData(); !bytes.Equal(got, want) {
+		t.Errorf("got sparse data %x, want %x", got, want)
+	}
+
+	// `s2` is not modified:
+	if got := s2.Estimate(); got != 100 {
+		t.Errorf("s2.Estimate: got %d, want 100", got)
+	}
+}
+
+func TestHLL_merge_sparseMixedPrecision(t *testing.T) {
+	rnd := rand.New(rand.NewSource(33))
+	s1, _ := hllplus.New(15, 20)
+	s2, _ := hllplus.New(13, 17)
+	exp, _ := hllplus.New(13, 17)
+
+	for range 500 {
+		n1, n2 := rnd.Uint64(), rnd.Uint64()
+		s1.Add(n1)
+		s2.Add(n2)
+		exp.Add(n1)
+		exp.Add(n2)
+	}
+
+	s1.Merge(s2)
+	if !s1.IsSparse() {
+		t.Error("expected sparse representation")
+	}
+	if got := s1.Precision(); got != 13 {
+		t.Errorf("Precision: got %d, want 13", got)
+	}
+	if got := s1.SparsePrecision(); got != 17 {
+		t.Errorf("SparsePrecision: got %d, want 17", got)
+	}
+	if got, want := s1.Estimate(), exp.Estimate(); got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+	if got, want := s1.Proto().GetSparseData(), exp.Proto().GetSparseData(); !bytes.Equal(got, want) {
+		t.Errorf("got sparse data %x, want %x", got, want)
+	}
+}
+
+func TestHLL_merge_sparseOverMax(t *testing.T) {
+	rnd := rand.New(rand.NewSource(33))
+	s1, _ := hllplus.New(12, 17)
+	s2, _ := hllplus.New(12, 17)
+	exp, _ := hllplus.New(12, 17)
+
+	for range 2_000 {
+		n1, n2 := rnd.Uint64(), rnd.Uint64()
+		s1.Add(n1)
+		s2.Add(n2)
+		exp.Add(n1)
+		exp.Add(n2)
+	}
+	if !s1.IsSparse() || !s2.IsSparse() {
+		t.Fatal("expected sparse representations")
+	}
+
+	s1.Merge(s2)
+	if s1.IsSparse() {
+		t.Error("expected normal representation")
+	}
+	if got, want := s1.Proto().GetData(), exp.Proto().GetData(); !bytes.Equal(got, want) {
+		t.Error("expected normal data to match")
+	}
+	if got := s1.Estimate(); got != 3_946 {
+		t.Errorf("got %d, want 3946", got)
+	}
+}
+
 func TestHLL_proto_initNormal(t *testing.T) {
 	rnd := rand.New(rand.NewSource(33))
 	subject, _ := hllplus.New(12, 17)
@@ -386,8 +560,8 @@ func TestHLL_proto_initNormal(t *testing.T) {
 	if subject.IsSparse() {
 		t.Error("expected normal representation")
 	}
-	if got := subject.Estimate(); got != 9_912 {
-		t.Errorf("got %d, want 9912", got)
+	if got := subject.Estimate(); got != 9_914 {
+		t.Errorf("got %d, want 9914", got)
 	}
 
 	msg := subject.Proto()
@@ -427,8 +601,8 @@ func TestHLL_proto_initNormal(t *testing.T) {
 	if got := restored.SparsePrecision(); got != 17 {
 		t.Errorf("sparse precision: got %d, want 17", got)
 	}
-	if got := restored.Estimate(); got != 9_912 {
-		t.Errorf("got %d, want 9912", got)
+	if got := restored.Estimate(); got != 9_914 {
+		t.Errorf("got %d, want 9914", got)
 	}
 }
 
@@ -487,3 +661,130 @@ func TestHLL_proto_initSparse(t *testing.T) {
 		t.Errorf("got %d, want 798", got)
 	}
 }
+
+func TestHLL_AddBatch(t *testing.T) {
+	for _, n := range []int{0, 10, 800, 3_000, 100_000} {
+		for _, p := range []uint8{10, 15} {
+			rnd := rand.New(rand.NewSource(33))
+			hashes := make([]uint64, n)
+			for i := range hashes {
+				hashes[i] = rnd.Uint64() & 0xffff_ffff_ff00_0000 // force some duplicates
+				if i != 0 && rnd.Intn(2) == 0 {
+					hashes[i] = hashes[rnd.Intn(i)] // repeat half of the values
+				}
+			}
+
+			exp, _ := hllplus.New(p, p+5)
+			for _, hash := range hashes {
+				exp.Add(hash)
+			}
+
+			subject, _ := hllplus.New(p, p+5)
+			subject.AddBatch(hashes[:n/3])
+			subject.AddBatch(hashes[n/3:])
+
+			if got, want := subject.IsSparse(), exp.IsSparse(); got != want {
+				t.Errorf("n=%d p=%d IsSparse: got %v, want %v", n, p, got, want)
+			}
+			if got, want := subject.Estimate(), exp.Estimate(); got != want {
+				t.Errorf("n=%d p=%d Estimate: got %d, want %d", n, p, got, want)
+			}
+			if got, want := marshalHLL(t, subject), marshalHLL(t, exp); !bytes.Equal(got, want) {
+				t.Errorf("n=%d p=%d: serialized state differs", n, p)
+			}
+		}
+	}
+}
+
+func TestHLL_AddBatch_fullRun(t *testing.T) {
+	// The sorted run of the batch fills the buffer exactly, it must be flushed
+	// before any further value is buffered.
+	rnd := rand.New(rand.NewSource(4))
+	hashes := make([]uint64, 512+257)
+	for i := range hashes {
+		hashes[i] = rnd.Uint64()
+	}
+
+	exp, _ := hllplus.New(10, 15)
+	subject, _ := hllplus.New(10, 15)
+	for _, hash := range hashes[:512] {
+		exp.Add(hash)
+		subject.Add(hash)
+	}
+	for _, hash := range hashes[512:] {
+		exp.Add(hash)
+	}
+	subject.AddBatch(hashes[512:])
+
+	if got, want := subject.IsSparse(), exp.IsSparse(); got != want {
+		t.Errorf("IsSparse: got %v, want %v", got, want)
+	}
+	if got, want := subject.Estimate(), exp.Estimate(); got != want {
+		t.Errorf("Estimate: got %d, want %d", got, want)
+	}
+	if got, want := marshalHLL(t, subject), marshalHLL(t, exp); !bytes.Equal(got, want) {
+		t.Error("serialized state differs")
+	}
+}
+
+func BenchmarkHLL_Add(b *testing.B) {
+	for _, n := range []int{1_000, 1_000_000} {
+		hashes := benchHashes(n)
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			for b.Loop() {
+				subject, _ := hllplus.New(15, 20)
+				for _, hash := range hashes {
+					subject.Add(hash)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkHLL_AddBatch(b *testing.B) {
+	for _, n := range []int{1_000, 1_000_000} {
+		hashes := benchHashes(n)
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			for b.Loop() {
+				subject, _ := hllplus.New(15, 20)
+				subject.AddBatch(hashes)
+			}
+		})
+	}
+}
+
+func benchHashes(n int) []uint64 {
+	rnd := rand.New(rand.NewSource(33))
+	hashes := make([]uint64, n)
+	for i := range hashes {
+		hashes[i] = rnd.Uint64()
+	}
+	return hashes
+}
+
+func TestEstimateData(t *testing.T) {
+	for _, n := range []int{0, 100, 10_000} {
+		subject := buildHLL(t, 12, 17, n, 33)
+		msg := subject.Proto()
+
+		got, err := hllplus.EstimateData(12, 17, msg.GetData(), msg.GetSparseData())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := subject.Estimate(); got != exp {
+			t.Errorf("n=%d: got %d, want %d", n, got, exp)
+		}
+	}
+
+	// overflowing varints are not counted:
+	sparse := []byte{0x01, 0x82, 0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0x03}
+	if got, err := hllplus.EstimateData(12, 17, nil, sparse); err != nil {
+		t.Fatal(err)
+	} else if got != 2 {
+		t.Errorf("got %d, want 2", got)
+	}
+
+	if _, err := hllplus.EstimateData(9, 17, nil, nil); err == nil {
+		t.Error("expected error")
+	}
+}
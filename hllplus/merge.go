@@ -0,0 +1,87 @@
+package hllplus
+
+import "errors"
+
+// MergeAll merges multiple sketches into a new sketch in a single pass. The
+// result has the lowest common precisions of all sketches, which are not
+// modified.
+//
+// The result is the same as merging all sketches into a clone of the first,
+// except that the sparse representation is retained as long as the merged
+// result fits. Sparse sketches are combined through a k-way merge of their
+// sorted values and normal registers are only allocated if needed.
+func MergeAll(sketches []*HLL) (*HLL, error) {
+	if len(sketches) == 0 {
+		return nil, errors.New("no sketches to merge")
+	}
+
+	// Find the lowest common precisions, skipping sketches without state like
+	// Merge does.
+	precision, sparsePrecision := sketches[0].precision, sketches[0].sparsePrecision
+	normal := false
+	for _, s := range sketches {
+		if len(s.normal) == 0 && s.sparse == nil {
+			continue
+		}
+
+		precision = min(precision, s.precision)
+		sparsePrecision = min(sparsePrecision, s.sparsePrecision)
+		normal = normal || s.sparse == nil
+	}
+
+	h, err := New(precision, sparsePrecision)
+	if err != nil {
+		return nil, err
+	}
+	h.estimator = sketches[0].estimator
+
+	if normal {
+		h.mergeAllNormal(sketches)
+		return h, nil
+	}
+
+	states := make([]*sparseState, 0, len(sketches))
+	for _, s := range sketches {
+		if s.sparse != nil {
+			states = append(states, s.sparse)
+		}
+	}
+	if h.sparse.MergeAll(states); h.sparse.OverMax() {
+		h.normalize()
+	}
+	return h, nil
+}
+
+// mergeAllNormal merges sketches into the normal registers of s.
+func (s *HLL) mergeAllNormal(sketches []*HLL) {
+	s.sparse = nil
+	s.ensureNormal()
+	for _, other := range sketches {
+		s.mergeNormal(other)
+	}
+}
+
+// mergeNormal merges other into the normal registers of s. The precision of
+// other must not be lower than the precision of s.
+func (s *HLL) mergeNormal(other *HLL) {
+	update := func(pos uint32, rhoW uint8) {
+		if s.normal[pos] < rhoW {
+			s.normal[pos] = rhoW
+		}
+	}
+
+	switch {
+	case other.sparse != nil && other.precision == s.precision:
+		other.sparse.Iterate(update)
+	case other.sparse != nil:
+		other.sparse.each(func(n uint32) {
+			update(computePosRhoW(other.sparse.decodeHash(n), s.precision))
+		})
+	case len(other.normal) == 0:
+		// nothing to merge
+	case other.precision == s.precision:
+		maxRegisters(s.normal[:len(other.normal)], other.normal)
+	default:
+		other.downgradeEach(s.precision, update)
+	}
+}
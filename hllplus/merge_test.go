@@ -0,0 +1,152 @@
+package hllplus_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/bsm/zetasketch/hllplus"
+)
+
+// newMergeSketches builds n sketches of the given precisions with m random
+// values each, as well as a single sketch at the lowest precisions with all
+// values.
+func newMergeSketches(n, m int, precisions [][2]uint8) ([]*hllplus.HLL, *hllplus.HLL) {
+	rnd := rand.New(rand.NewSource(33))
+
+	p, sp := precisions[0][0], precisions[0][1]
+	for _, pp := range precisions {
+		p, sp = min(p, pp[0]), min(sp, pp[1])
+	}
+	exp, _ := hllplus.New(p, sp)
+
+	sketches := make([]*hllplus.HLL, 0, n)
+	for i := range n {
+		pp := precisions[i%len(precisions)]
+		s, _ := hllplus.New(pp[0], pp[1])
+		for range m {
+			x := rnd.Uint64()
+			s.Add(x)
+			exp.Add(x)
+		}
+		sketches = append(sketches, s)
+	}
+	return sketches, exp
+}
+
+func TestMergeAll_sparse(t *testing.T) {
+	sketches, exp := newMergeSketches(20, 100, [][2]uint8{{15, 20}})
+	sketches = append(sketches, sketches[0].Clone()) // overlapping values
+
+	subject, err := hllplus.MergeAll(sketches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !subject.IsSparse() {
+		t.Error("expected sparse representation")
+	}
+	if got, want := subject.Proto().GetSparseData(), exp.Proto().GetSparseData(); !bytes.Equal(got, want) {
+		t.Error("expected sparse data to match")
+	}
+	if got, want := subject.Estimate(), exp.Estimate(); got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+
+	// inputs are not modified:
+	if got := sketches[0].Estimate(); got != 100 {
+		t.Errorf("sketches[0].Estimate: got %d, want 100", got)
+	}
+}
+
+func TestMergeAll_sparseMixedPrecision(t *testing.T) {
+	sketches, exp := newMergeSketches(20, 100, [][2]uint8{{15, 20}, {13, 20}, {15, 17}})
+
+	subject, err := hllplus.MergeAll(sketches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := subject.Precision(), uint8(13); got != want {
+		t.Errorf("Precision: got %d, want %d", got, want)
+	}
+	if got, want := subject.SparsePrecision(), uint8(17); got != want {
+		t.Errorf("SparsePrecision: got %d, want %d", got, want)
+	}
+	if !subject.IsSparse() {
+		t.Error("expected sparse representation")
+	}
+	if got, want := subject.Proto().GetSparseData(), exp.Proto().GetSparseData(); !bytes.Equal(got, want) {
+		t.Error("expected sparse data to match")
+	}
+}
+
+func TestMergeAll_overMax(t *testing.T) {
+	sketches, exp := newMergeSketches(20, 500, [][2]uint8{{12, 17}, {13, 18}})
+	if !sketches[0].IsSparse() {
+		t.Fatal("expected sparse inputs")
+	}
+
+	subject, err := hllplus.MergeAll(sketches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject.IsSparse() {
+		t.Error("expected normal representation")
+	}
+	if got, want := subject.Proto().GetData(), exp.Proto().GetData(); !bytes.Equal(got, want) {
+		t.Error("expected normal data to match")
+	}
+}
+
+func TestMergeAll_normal(t *testing.T) {
+	sketches, _ := newMergeSketches(20, 100, [][2]uint8{{15, 20}, {14, 19}})
+	normal, _ := hllplus.NewNormal(15)
+	for i := range 10_000 {
+		normal.Add(uint64(i) * 0x9e3779b97f4a7c15)
+	}
+	sketches = append(sketches, normal)
+
+	exp := sketches[0].Clone()
+	for _, s := range sketches[1:] {
+		exp.Merge(s)
+	}
+
+	subject, err := hllplus.MergeAll(sketches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject.IsSparse() {
+		t.Error("expected normal representation")
+	}
+	if got, want := subject.Precision(), uint8(14); got != want {
+		t.Errorf("Precision: got %d, want %d", got, want)
+	}
+	if got, want := subject.Proto().GetData(), exp.Proto().GetData(); !bytes.Equal(got, want) {
+		t.Error("expected normal data to match")
+	}
+}
+
+func TestMergeAll_empty(t *testing.T) {
+	if _, err := hllplus.MergeAll(nil); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func BenchmarkMergeAll(b *testing.B) {
+	sketches, _ := newMergeSketches(10_000, 20, [][2]uint8{{15, 20}})
+
+	b.Run("sequential", func(b *testing.B) {
+		for b.Loop() {
+			h := sketches[0].Clone()
+			for _, s := range sketches[1:] {
+				h.Merge(s)
+			}
+		}
+	})
+	b.Run("MergeAll", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := hllplus.MergeAll(sketches); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
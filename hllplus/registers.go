@@ -0,0 +1,118 @@
+package hllplus
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+// Word-at-a-time (SWAR) kernels over normal registers. Architectures with
+// dedicated SIMD implementations replace maxRegisters and countZeroRegisters,
+// see registers_amd64.go.
+
+const (
+	lo7 = 0x7f7f7f7f7f7f7f7f
+	hi1 = 0x8080808080808080
+)
+
+// maxRegistersGeneric sets each register in dst to the maximum of itself and
+// the corresponding register in src, which must not be shorter than dst.
+func maxRegistersGeneric(dst, src []byte) {
+	src = src[:len(dst)]
+	for len(dst) >= 8 {
+		a := binary.LittleEndian.Uint64(dst)
+		b := binary.LittleEndian.Uint64(src)
+
+		// High bit of each byte is set where a >= b: compare the high bits
+		// directly and fall back to the lower 7 bits where they are equal.
+		low := ((a | hi1) - (b & lo7)) & hi1
+		ge := (a&^b | ^(a^b)&low) & hi1
+		mask := (ge - ge>>7) | ge
+
+		binary.LittleEndian.PutUint64(dst, a&mask|b&^mask)
+		dst, src = dst[8:], src[8:]
+	}
+	for i, rho := range src {
+		dst[i] = max(dst[i], rho)
+	}
+}
+
+// countZeroRegistersGeneric returns the number of zero registers.
+func countZeroRegistersGeneric(regs []byte) int {
+	n := 0
+	for len(regs) >= 8 {
+		w := binary.LittleEndian.Uint64(regs)
+
+		// High bit of each byte is set where all of its lower 7 bits and its
+		// high bit are zero.
+		n += bits.OnesCount64(^((w&lo7 + lo7) | w) & hi1)
+		regs = regs[8:]
+	}
+	for _, rho := range regs {
+		if rho == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// harmonicSum computes the sum of 2^-rho over all registers.
+//
+// Registers are counted per value first and the powers of two are summed
+// afterwards. Each partial sum is a multiple of 2^-maxRho and not larger than
+// the number of registers, so as long as both fit into the 53 bit mantissa of
+// a float64, all additions are exact and the result does not depend on the
+// order of summation. Otherwise, registers are summed sequentially.
+func harmonicSum(regs []byte) float64 {
+	var counts [256]uint32
+	countRegisters(regs, &counts)
+
+	maxRho := 0
+	for rho, n := range counts {
+		if n != 0 {
+			maxRho = rho
+		}
+	}
+	if bits.Len(uint(len(regs)))+maxRho > 53 {
+		return harmonicSumScalar(regs)
+	}
+
+	sum := 0.0
+	for rho, n := range counts[:maxRho+1] {
+		sum += math.Ldexp(float64(n), -rho)
+	}
+	return sum
+}
+
+// countRegisters computes the histogram of register values.
+func countRegisters(regs []byte, counts *[256]uint32) {
+	// interleaved counters avoid dependencies between consecutive registers
+	var c [4][256]uint32
+	i := 0
+	for ; i+4 <= len(regs); i += 4 {
+		c[0][regs[i]]++
+		c[1][regs[i+1]]++
+		c[2][regs[i+2]]++
+		c[3][regs[i+3]]++
+	}
+	for ; i < len(regs); i++ {
+		c[0][regs[i]]++
+	}
+
+	for rho := range counts {
+		counts[rho] = c[0][rho] + c[1][rho] + c[2][rho] + c[3][rho]
+	}
+}
+
+// harmonicSumScalar computes the sum of 2^-rho over all registers, in order.
+func harmonicSumScalar(regs []byte) float64 {
+	sum := 0.0
+	for _, c := range regs {
+		// Compute sum += math.pow(2, -v) without actually performing a floating point exponent
+		// computation (which is expensive). v can be at most 64 - precision + 1 and the minimum
+		// precision is larger than 2 (see MINIMUM_PRECISION), so this left shift can not overflow.
+		x := 1 << c
+		sum += 1.0 / float64(x)
+	}
+	return sum
+}
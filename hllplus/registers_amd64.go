@@ -0,0 +1,23 @@
+//go:build !purego
+
+package hllplus
+
+// maxRegisters processes blocks of 32 registers using SSE2 (PMAXUB).
+func maxRegisters(dst, src []byte) {
+	src = src[:len(dst)]
+	n := len(dst) &^ 31
+	maxBlocksSSE2(dst[:n], src[:n])
+	maxRegistersGeneric(dst[n:], src[n:])
+}
+
+// countZeroRegisters processes blocks of 16 registers using SSE2 (PCMPEQB).
+func countZeroRegisters(regs []byte) int {
+	n := len(regs) &^ 15
+	return countZeroBlocksSSE2(regs[:n]) + countZeroRegistersGeneric(regs[n:])
+}
+
+//go:noescape
+func maxBlocksSSE2(dst, src []byte)
+
+//go:noescape
+func countZeroBlocksSSE2(regs []byte) int
@@ -0,0 +1,71 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// func maxBlocksSSE2(dst, src []byte)
+// len(dst) must be a multiple of 32 and len(src) >= len(dst).
+TEXT ·maxBlocksSSE2(SB), NOSPLIT, $0-48
+	MOVQ dst_base+0(FP), DI
+	MOVQ src_base+24(FP), SI
+	MOVQ dst_len+8(FP), CX
+	SHRQ $5, CX
+	JZ   done
+
+loop:
+	MOVOU  (DI), X0
+	MOVOU  16(DI), X1
+	MOVOU  (SI), X2
+	MOVOU  16(SI), X3
+	PMAXUB X2, X0
+	PMAXUB X3, X1
+	MOVOU  X0, (DI)
+	MOVOU  X1, 16(DI)
+	ADDQ   $32, DI
+	ADDQ   $32, SI
+	DECQ   CX
+	JNZ    loop
+
+done:
+	RET
+
+// func countZeroBlocksSSE2(regs []byte) int
+// len(regs) must be a multiple of 16.
+TEXT ·countZeroBlocksSSE2(SB), NOSPLIT, $0-32
+	MOVQ regs_base+0(FP), SI
+	MOVQ regs_len+8(FP), CX
+	PXOR X1, X1 // zero
+	PXOR X2, X2 // totals, 2x uint64
+	SHRQ $4, CX
+	JZ   done
+
+outer:
+	// byte counters can take at most 255 blocks
+	MOVQ CX, BX
+	CMPQ BX, $255
+	JLE  inner_init
+	MOVQ $255, BX
+
+inner_init:
+	SUBQ BX, CX
+	PXOR X3, X3 // counters, 16x uint8
+
+inner:
+	MOVOU   (SI), X0
+	PCMPEQB X1, X0
+	PSUBB   X0, X3
+	ADDQ    $16, SI
+	DECQ    BX
+	JNZ     inner
+
+	PSADBW X1, X3
+	PADDQ  X3, X2
+	TESTQ  CX, CX
+	JNZ    outer
+
+done:
+	MOVQ  X2, AX
+	PSRLO $8, X2
+	MOVQ  X2, DX
+	ADDQ  DX, AX
+	MOVQ  AX, ret+24(FP)
+	RET
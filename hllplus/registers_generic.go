@@ -0,0 +1,11 @@
+//go:build !amd64 || purego
+
+package hllplus
+
+func maxRegisters(dst, src []byte) {
+	maxRegistersGeneric(dst, src)
+}
+
+func countZeroRegisters(regs []byte) int {
+	return countZeroRegistersGeneric(regs)
+}
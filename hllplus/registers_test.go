@@ -0,0 +1,149 @@
+package hllplus_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/bsm/zetasketch/hllplus"
+)
+
+// randomRegisters returns n registers with values up to maxRho, where roughly
+// every zeroEvery-th register is zero.
+func randomRegisters(rnd *rand.Rand, n, maxRho, zeroEvery int) []byte {
+	regs := make([]byte, n)
+	for i := range regs {
+		if rnd.Intn(zeroEvery) != 0 {
+			regs[i] = byte(rnd.Intn(maxRho + 1))
+		}
+	}
+	return regs
+}
+
+func TestMaxRegisters(t *testing.T) {
+	rnd := rand.New(rand.NewSource(33))
+	for _, n := range []int{0, 1, 7, 8, 31, 33, 1 << 10, 1<<10 + 5} {
+		for _, maxRho := range []int{55, 255} {
+			a := randomRegisters(rnd, n, maxRho, 3)
+			b := randomRegisters(rnd, n, maxRho, 3)
+
+			exp := make([]byte, n)
+			for i := range exp {
+				exp[i] = max(a[i], b[i])
+			}
+
+			for name, fn := range map[string]func(dst, src []byte){
+				"default": hllplus.MaxRegisters,
+				"generic": hllplus.MaxRegistersGeneric,
+			} {
+				got := bytes.Clone(a)
+				fn(got, b)
+				if !bytes.Equal(got, exp) {
+					t.Errorf("%s n=%d maxRho=%d: got %v, want %v", name, n, maxRho, got, exp)
+				}
+			}
+		}
+	}
+}
+
+func TestCountZeroRegisters(t *testing.T) {
+	rnd := rand.New(rand.NewSource(33))
+	for _, n := range []int{0, 1, 7, 8, 15, 17, 1 << 10, 1<<10 + 5, 1 << 16} {
+		for _, zeroEvery := range []int{1, 2, 100} {
+			regs := randomRegisters(rnd, n, 255, zeroEvery)
+
+			exp := 0
+			for _, rho := range regs {
+				if rho == 0 {
+					exp++
+				}
+			}
+
+			if got := hllplus.CountZeroRegisters(regs); got != exp {
+				t.Errorf("default n=%d: got %d, want %d", n, got, exp)
+			}
+			if got := hllplus.CountZeroRegistersGeneric(regs); got != exp {
+				t.Errorf("generic n=%d: got %d, want %d", n, got, exp)
+			}
+		}
+	}
+}
+
+func TestHarmonicSum(t *testing.T) {
+	rnd := rand.New(rand.NewSource(33))
+	for _, n := range []int{0, 3, 1 << 10, 1 << 18, 1 << 24} {
+		for _, maxRho := range []int{20, 40, 64, 255} {
+			regs := randomRegisters(rnd, n, maxRho, 4)
+			got, exp := hllplus.HarmonicSum(regs), hllplus.HarmonicSumScalar(regs)
+			if math.Float64bits(got) != math.Float64bits(exp) {
+				t.Errorf("n=%d maxRho=%d: got %v, want %v", n, maxRho, got, exp)
+			}
+		}
+	}
+}
+
+func BenchmarkMaxRegisters(b *testing.B) {
+	rnd := rand.New(rand.NewSource(33))
+	for _, p := range []int{15, 20} {
+		dst := randomRegisters(rnd, 1<<p, 30, 4)
+		src := randomRegisters(rnd, 1<<p, 30, 4)
+
+		b.Run(fmt.Sprintf("p=%d/scalar", p), func(b *testing.B) {
+			for b.Loop() {
+				for i, rho := range src {
+					if dst[i] < rho {
+						dst[i] = rho
+					}
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("p=%d/generic", p), func(b *testing.B) {
+			for b.Loop() {
+				hllplus.MaxRegistersGeneric(dst, src)
+			}
+		})
+		b.Run(fmt.Sprintf("p=%d/default", p), func(b *testing.B) {
+			for b.Loop() {
+				hllplus.MaxRegisters(dst, src)
+			}
+		})
+	}
+}
+
+func BenchmarkHarmonicSum(b *testing.B) {
+	rnd := rand.New(rand.NewSource(33))
+	for _, p := range []int{15, 20} {
+		regs := randomRegisters(rnd, 1<<p, 30, 4)
+
+		b.Run(fmt.Sprintf("p=%d/scalar", p), func(b *testing.B) {
+			for b.Loop() {
+				hllplus.HarmonicSumScalar(regs)
+			}
+		})
+		b.Run(fmt.Sprintf("p=%d/default", p), func(b *testing.B) {
+			for b.Loop() {
+				hllplus.HarmonicSum(regs)
+			}
+		})
+	}
+}
+
+func BenchmarkCountZeroRegisters(b *testing.B) {
+	rnd := rand.New(rand.NewSource(33))
+	for _, p := range []int{15, 20} {
+		regs := randomRegisters(rnd, 1<<p, 30, 4)
+
+		b.Run(fmt.Sprintf("p=%d/generic", p), func(b *testing.B) {
+			for b.Loop() {
+				hllplus.CountZeroRegistersGeneric(regs)
+			}
+		})
+		b.Run(fmt.Sprintf("p=%d/default", p), func(b *testing.B) {
+			for b.Loop() {
+				hllplus.CountZeroRegisters(regs)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package hllplus
+
+// Jaccard is an estimate of the Jaccard similarity of two sets.
+type Jaccard struct {
+	// Similarity is the estimated Jaccard index |A ∩ B| / |A ∪ B|, between 0
+	// and 1. It is 0 if both sets are empty.
+	Similarity float64
+	// StdError is the absolute standard error of the estimate. If both
+	// sketches were sparse, the similarity is exact up to hash collisions and
+	// StdError is the expected overestimate caused by collisions instead.
+	StdError float64
+}
+
+// Similarity estimates the Jaccard similarity of the sets represented by a and
+// b, which are not modified. Sketches with different precisions are compared
+// at their lowest common precisions.
+//
+// If both sketches are sparse, their sparse values are compared directly,
+// which is exact up to hash collisions between values. The error is not a
+// standard error but the expected collision bias: the share of values only in a
+// and values only in b which are expected to collide and count as shared.
+// Otherwise, the similarity is derived by inclusion–exclusion from the
+// cardinality estimates of a, b and their union, the error from the sum of
+// their standard errors.
+func Similarity(a, b *HLL) Jaccard {
+	precision := min(a.precision, b.precision)
+	sparsePrecision := min(a.sparsePrecision, b.sparsePrecision)
+	a, b = a.downgraded(precision, sparsePrecision), b.downgraded(precision, sparsePrecision)
+
+	if a.sparse != nil && b.sparse != nil {
+		return sparseSimilarity(a.sparse, b.sparse)
+	}
+
+	union := a.Clone()
+	union.Merge(b)
+
+	na, ea := a.estimateWithError()
+	nb, eb := b.estimateWithError()
+	nu, eu := union.estimateWithError()
+	if nu == 0 {
+		return Jaccard{}
+	}
+
+	inter := min(max(0, na+nb-nu), na, nb)
+	return Jaccard{
+		Similarity: float64(inter) / float64(nu),
+		StdError:   min((ea+eb+eu)/float64(nu), 1),
+	}
+}
+
+// downgraded returns s or, if necessary, a copy of s with lower precisions.
+func (s *HLL) downgraded(precision, sparsePrecision uint8) *HLL {
+	if s.precision == precision && s.sparsePrecision == sparsePrecision {
+		return s
+	}
+
+	s = s.Clone()
+	_ = s.Downgrade(precision, sparsePrecision)
+	return s
+}
+
+func sparseSimilarity(a, b *sparseState) Jaccard {
+	a, b = a.flushed(), b.flushed()
+
+	inter := 0
+	ai, bi := a.data.Iterator(), b.data.Iterator()
+	x, xok := ai.Next()
+	y, yok := bi.Next()
+	for xok && yok {
+		switch {
+		case x < y:
+			x, xok = ai.Next()
+		case y < x:
+			y, yok = bi.Next()
+		default:
+			inter++
+			x, xok = ai.Next()
+			y, yok = bi.Next()
+		}
+	}
+	onlyA, onlyB := a.data.Count()-inter, b.data.Count()-inter
+
+	union := inter + onlyA + onlyB
+	if union == 0 {
+		return Jaccard{}
+	}
+
+	numBuckets := float64(uint64(1) << a.sparsePrecision)
+	return Jaccard{
+		Similarity: float64(inter) / float64(union),
+		StdError:   float64(onlyA) * float64(onlyB) / numBuckets / float64(union),
+	}
+}
@@ -0,0 +1,120 @@
+package hllplus_test
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/bsm/zetasketch/hllplus"
+)
+
+func TestSimilarity(t *testing.T) {
+	cases := []struct {
+		name           string
+		a, b           [2]uint8 // precisions
+		n              int      // values per sketch, half of which are shared
+		sparse, normal bool     // expected representations
+		exp            float64
+	}{
+		{"sparse", [2]uint8{15, 20}, [2]uint8{15, 20}, 2_000, true, false, 1.0 / 3},
+		{"sparse mixed", [2]uint8{15, 20}, [2]uint8{14, 18}, 2_000, true, false, 1.0 / 3},
+		{"normal", [2]uint8{12, 17}, [2]uint8{12, 17}, 100_000, false, true, 1.0 / 3},
+		{"normal mixed", [2]uint8{14, 19}, [2]uint8{12, 17}, 100_000, false, true, 1.0 / 3},
+		{"sparse and normal", [2]uint8{15, 20}, [2]uint8{12, 17}, 20_000, false, false, 1.0 / 3},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, _ := hllplus.New(tc.a[0], tc.a[1])
+			b, _ := hllplus.New(tc.b[0], tc.b[1])
+			for i := range tc.n {
+				a.Add(uint64(i) * 0x9e3779b97f4a7c15)
+				b.Add(uint64(i+tc.n/2) * 0x9e3779b97f4a7c15)
+			}
+			if tc.sparse && (!a.IsSparse() || !b.IsSparse()) {
+				t.Fatal("expected sparse representations")
+			}
+			if tc.normal && (a.IsSparse() || b.IsSparse()) {
+				t.Fatal("expected normal representations")
+			}
+
+			var numBuffered [2]int
+			if tc.sparse {
+				numBuffered = [2]int{a.NumBuffered(), b.NumBuffered()}
+				if numBuffered[0] == 0 || numBuffered[1] == 0 {
+					t.Fatal("expected buffered values")
+				}
+			}
+
+			est := a.Clone().Estimate()
+			got := hllplus.Similarity(a, b)
+			if math.Abs(got.Similarity-tc.exp) > 3*got.StdError+0.001 {
+				t.Errorf("got %+v, want %.4f", got, tc.exp)
+			}
+			if !(got.StdError >= 0 && got.StdError < 0.1) {
+				t.Errorf("got unexpected error %v", got.StdError)
+			}
+
+			// inputs are not modified
+			if tc.sparse && (a.NumBuffered() != numBuffered[0] || b.NumBuffered() != numBuffered[1]) {
+				t.Errorf("NumBuffered: got %d/%d, want %v", a.NumBuffered(), b.NumBuffered(), numBuffered)
+			}
+			if got := a.Estimate(); got != est {
+				t.Errorf("Estimate: got %d, want %d", got, est)
+			}
+			if got := a.Precision(); got != tc.a[0] {
+				t.Errorf("Precision: got %d, want %d", got, tc.a[0])
+			}
+		})
+	}
+}
+
+func TestSimilarity_edgeCases(t *testing.T) {
+	empty, _ := hllplus.New(15, 20)
+	if got := hllplus.Similarity(empty, empty); got != (hllplus.Jaccard{}) {
+		t.Errorf("empty: got %+v", got)
+	}
+
+	a := buildHLL(t, 15, 20, 1_000, 33)
+	if got := hllplus.Similarity(a, a); got.Similarity != 1 || got.StdError != 0 {
+		t.Errorf("identical: got %+v", got)
+	}
+	if got := hllplus.Similarity(a, empty); got.Similarity != 0 {
+		t.Errorf("empty b: got %+v", got)
+	}
+
+	n := buildHLL(t, 12, 17, 100_000, 33)
+	if got := hllplus.Similarity(n, n); math.Abs(got.Similarity-1) > 3*got.StdError {
+		t.Errorf("identical normal: got %+v", got)
+	}
+}
+
+func TestSimilarity_concurrent(t *testing.T) {
+	a := buildHLL(t, 15, 20, 1_000, 33)
+	b := buildHLL(t, 12, 17, 100_000, 34)
+	if !a.IsSparse() || a.NumBuffered() == 0 || b.IsSparse() {
+		t.Fatal("expected a sparse sketch with buffered values and a normal one")
+	}
+
+	exp := hllplus.Similarity(a.Clone(), b.Clone())
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			x, y := a, b
+			if i%2 == 1 {
+				x, y = b, a
+			}
+			if got := hllplus.Similarity(x, y); got != exp {
+				t.Errorf("got %+v, want %+v", got, exp)
+			}
+			if _, err := x.EstimateBounds(0.95); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if a.NumBuffered() == 0 {
+		t.Error("expected buffered values to be retained")
+	}
+}
@@ -3,6 +3,7 @@ package hllplus
 import (
 	"encoding/binary"
 	"math"
+	"slices"
 	"sort"
 	"sync"
 )
@@ -29,10 +30,7 @@ func newSparseState(normalPrecision, sparsePrecision uint8, state []byte) *spars
 	maxDataLen := m * 3 / 4
 	maxBufferLen := m / 4
 
-	encodedFlag := uint32(1 << sparsePrecision)
-	if n := normalPrecision + sparseRhoWBits; n > sparsePrecision {
-		encodedFlag = 1 << n
-	}
+	encodedFlag := sparseEncodedFlag(normalPrecision, sparsePrecision)
 
 	// restore state from passed data (optional):
 	// Allocate lazily with a small initial capacity instead of pre-sizing for the
@@ -54,6 +52,15 @@ func newSparseState(normalPrecision, sparsePrecision uint8, state []byte) *spars
 	}
 }
 
+// sparseEncodedFlag returns the flag which marks sparse values with an encoded
+// rhoW.
+func sparseEncodedFlag(normalPrecision, sparsePrecision uint8) uint32 {
+	if n := normalPrecision + sparseRhoWBits; n > sparsePrecision {
+		return 1 << n
+	}
+	return 1 << sparsePrecision
+}
+
 func (s *sparseState) Add(hash uint64) {
 	val := s.encode(hash)
 	if s.buffer.Add(val); s.buffer.Len() >= s.maxBufferLen {
@@ -61,17 +68,108 @@ func (s *sparseState) Add(hash uint64) {
 	}
 }
 
-// Linear counting over the number of empty sparse buckets.
+// AddBatch adds hashes until the buffer is flushed and returns the remaining
+// hashes. Values are flushed at exactly the same points as by Add, i.e. once
+// maxBufferLen distinct values have been buffered.
+func (s *sparseState) AddBatch(hashes []uint64) []uint64 {
+	// Large batches bypass the buffer set: values are collected in a sorted run
+	// of distinct values first and only the remainder is checked against it.
+	var run []uint32
+	if s.buffer.Len() == 0 && len(hashes) >= s.maxBufferLen {
+		run, hashes = s.sortedRun(hashes)
+	}
+	if len(run) >= s.maxBufferLen {
+		s.flushRun(run)
+		return hashes
+	}
+
+	for i, hash := range hashes {
+		val := s.encode(hash)
+		if _, ok := slices.BinarySearch(run, val); !ok {
+			s.buffer.Add(val)
+		}
+		if len(run)+s.buffer.Len() >= s.maxBufferLen {
+			s.flushRun(run)
+			return hashes[i+1:]
+		}
+	}
+
+	for _, val := range run {
+		s.buffer.Add(val)
+	}
+	return nil
+}
+
+// sortedRun encodes a prefix of hashes into a sorted run of up to maxBufferLen
+// distinct values. It stops once the remaining capacity becomes too small to
+// be filled efficiently and returns the unprocessed hashes.
+func (s *sparseState) sortedRun(hashes []uint64) ([]uint32, []uint64) {
+	run := make([]uint32, 0, s.maxBufferLen)
+	for len(hashes) != 0 {
+		n := min(s.maxBufferLen-len(run), len(hashes))
+		if len(run) != 0 && n < s.maxBufferLen/16 {
+			break
+		}
+
+		for _, hash := range hashes[:n] {
+			run = append(run, s.encode(hash))
+		}
+		hashes = hashes[n:]
+
+		slices.Sort(run)
+		run = slices.Compact(run)
+	}
+	return run, hashes
+}
+
+// flushRun flushes the buffer together with run, a sorted run of distinct
+// values which are not contained in the buffer.
+func (s *sparseState) flushRun(run []uint32) {
+	buffered := s.buffer.Flush()
+	values := recycleDeltaSlice(len(run) + len(buffered))
+	for len(run) != 0 && len(buffered) != 0 {
+		if run[0] < buffered[0] {
+			values.Append(run[0])
+			run = run[1:]
+		} else {
+			values.Append(buffered[0])
+			buffered = buffered[1:]
+		}
+	}
+	for _, x := range run {
+		values.Append(x)
+	}
+	for _, x := range buffered {
+		values.Append(x)
+	}
+
+	result := recycleDeltaSlice(s.data.Len() + values.Len())
+	mergeDeltaSlices(result, s.data, values)
+	values.Release()
+
+	s.data.Release()
+	s.data = result
+}
+
+// Linear counting over the number of empty sparse buckets. Buffered values are
+// flushed into a copy, s is not modified.
 func (s *sparseState) Estimate() int64 {
-	s.Flush()
+	return estimateSparse(s.flushed().data.Count(), s.sparsePrecision)
+}
 
-	mm := 1 << s.sparsePrecision
+// estimateSparse applies linear counting to count non-empty sparse buckets.
+func estimateSparse(count int, sparsePrecision uint8) int64 {
+	mm := 1 << sparsePrecision
 	numBuckets := float64(mm)
-	numZeros := numBuckets - float64(s.data.Count())
+	numZeros := numBuckets - float64(count)
 	return int64(numBuckets*math.Log(numBuckets/numZeros) + 0.5)
 }
 
 func (s *sparseState) Clone() *sparseState {
+	if s == nil {
+		return nil
+	}
+
 	return &sparseState{
 		normalPrecision: s.normalPrecision,
 		sparsePrecision: s.sparsePrecision,
@@ -90,47 +188,102 @@ func (s *sparseState) Flush() {
 		return
 	}
 
-	result := recycleDeltaSlice(s.data.Len())
-	buffered := s.buffer.Flush()
+	buffered := recycleDeltaSlice(s.buffer.Len())
+	for _, x := range s.buffer.Flush() {
+		buffered.Append(x)
+	}
 
 	// merge existing data and buffered
-	s.data.Iterate(func(x uint32) {
-		if len(buffered) == 0 {
-			result.Append(x)
-			return
-		}
+	result := recycleDeltaSlice(s.data.Len() + buffered.Len())
+	mergeDeltaSlices(result, s.data, buffered)
+	buffered.Release()
 
-		for len(buffered) > 0 {
-			b := buffered[0]
-
-			if b <= x {
-				// shift buffered element so it is not used on next iteration
-				buffered = buffered[1:]
-			}
-
-			if b < x {
-				// append all buffered elements, smaller than stored one
-				result.Append(b)
-			} else {
-				// append last element (stored, largest one)
-				result.Append(x)
-				break
-			}
+	// replace data
+	s.data.Release()
+	s.data = result
+}
+
+// flushed returns s or, if it has buffered values, a flushed copy of s.
+func (s *sparseState) flushed() *sparseState {
+	if s.buffer.Len() == 0 {
+		return s
+	}
+
+	s = s.Clone()
+	s.Flush()
+	return s
+}
+
+func (s *sparseState) OverMax() bool {
+	return s.data.Len() > s.maxDataLen
+}
+
+// Merge merges other into s. Values of other are re-encoded if its
+// precisions differ, they must not be lower than the precisions of s.
+func (s *sparseState) Merge(other *sparseState) {
+	if s.normalPrecision != other.normalPrecision || s.sparsePrecision != other.sparsePrecision {
+		other.each(func(n uint32) {
+			s.buffer.Add(s.encode(other.decodeHash(n)))
+		})
+		s.Flush()
+		return
+	}
+
+	// merge sorted data streams, then flush the remaining buffered values
+	s.Flush()
+	result := recycleDeltaSlice(s.data.Len() + other.data.Len())
+	mergeDeltaSlices(result, s.data, other.data)
+	s.data.Release()
+	s.data = result
+
+	other.buffer.Iterate(s.buffer.Add)
+	s.Flush()
+}
+
+// MergeAll merges all states into s. Data of states with the same precisions
+// is merged as sorted streams, all other values are re-encoded, sorted and
+// merged as an additional stream.
+func (s *sparseState) MergeAll(states []*sparseState) {
+	s.Flush()
+
+	iters := make([]*deltaIterator, 0, len(states)+2)
+	iters = append(iters, s.data.Iterator())
+
+	var extra []uint32
+	for _, t := range states {
+		if s.normalPrecision != t.normalPrecision || s.sparsePrecision != t.sparsePrecision {
+			t.each(func(n uint32) {
+				extra = append(extra, s.encode(t.decodeHash(n)))
+			})
+			continue
 		}
-	})
 
-	// append remaining
-	for _, x := range buffered {
-		result.Append(x)
+		iters = append(iters, t.data.Iterator())
+		t.buffer.Iterate(func(n uint32) {
+			extra = append(extra, n)
+		})
 	}
 
-	// replace data
+	slices.Sort(extra)
+	buffered := recycleDeltaSlice(len(extra))
+	for _, x := range slices.Compact(extra) {
+		buffered.Append(x)
+	}
+	iters = append(iters, buffered.Iterator())
+
+	result := recycleDeltaSlice(s.data.Len())
+	mergeDeltaIterators(result, iters)
+	buffered.Release()
+
 	s.data.Release()
 	s.data = result
 }
 
-func (s *sparseState) OverMax() bool {
-	return s.data.Len() > s.maxDataLen
+// Downgrade returns a copy of s, re-encoded to lower precisions.
+func (s *sparseState) Downgrade(normalPrecision, sparsePrecision uint8) *sparseState {
+	t := newSparseState(normalPrecision, sparsePrecision, nil)
+	t.Merge(s)
+	return t
 }
 
 func (s *sparseState) Iterate(cb func(pos uint32, rhoW uint8)) {
@@ -142,6 +295,11 @@ func (s *sparseState) Iterate(cb func(pos uint32, rhoW uint8)) {
 	s.buffer.Iterate(handle)
 }
 
+func (s *sparseState) each(cb func(n uint32)) {
+	s.data.Iterate(cb)
+	s.buffer.Iterate(cb)
+}
+
 func (s *sparseState) GetData() ([]byte, int) {
 	s.Flush()
 	d := s.data.Clone()
@@ -184,6 +342,26 @@ func (s *sparseState) decode(sparseValue uint32) (pos uint32, rhoW uint8) {
 	return pos, rhoW
 }
 
+// decodeHash reconstructs a hash from sparseValue. The returned hash encodes
+// to the same pos and rhoW at the current and at any lower precisions.
+func (s *sparseState) decodeHash(sparseValue uint32) uint64 {
+	if sparseValue&s.encodedFlag == 0 {
+		// The sparse index contains at least one non-zero bit below the normal
+		// index, remaining bits are irrelevant.
+		return uint64(sparseValue) << (64 - s.sparsePrecision)
+	}
+
+	// Restore the normal index and place the first non-zero bit according to
+	// the encoded sparse rhoW'. No bit is set if the sparse rhoW' indicates
+	// that all remaining bits of the original hash were zero.
+	pos := (sparseValue ^ s.encodedFlag) >> sparseRhoWBits
+	hash := uint64(pos) << (64 - s.normalPrecision)
+	if n := uint32(s.sparsePrecision) + sparseValue&sparseRhowMask; n <= 64 {
+		hash |= 1 << (64 - n)
+	}
+	return hash
+}
+
 // --------------------------------------------------------------------
 
 type uint32Set map[uint32]struct{}
@@ -255,6 +433,25 @@ func (s uvarintSlice) Iterate(fn func(uint32)) {
 	}
 }
 
+// Count returns the number of values, i.e. the number of varints which
+// Iterate would decode.
+func (s uvarintSlice) Count() int {
+	// Count the terminating bytes of varints, unless a sequence of continuation
+	// bytes is long enough for the varint to overflow.
+	n, run := 0, 0
+	for _, b := range s {
+		if b < 0x80 {
+			n++
+			run = 0
+		} else if run++; run == binary.MaxVarintLen64-1 {
+			n = 0
+			s.Iterate(func(uint32) { n++ })
+			return n
+		}
+	}
+	return n
+}
+
 // --------------------------------------------------------------------
 
 var deltaSlicePool sync.Pool
@@ -321,6 +518,10 @@ func (s *deltaSlice) Iterate(fn func(uint32)) {
 	})
 }
 
+func (s *deltaSlice) Iterator() *deltaIterator {
+	return &deltaIterator{nums: s.nums}
+}
+
 func (s *deltaSlice) Bytes() []byte {
 	return s.nums
 }
@@ -334,3 +535,97 @@ func (s *deltaSlice) SetData(p []byte) {
 		s.size++
 	})
 }
+
+// mergeDeltaSlices appends the sorted union of a and b to dst.
+func mergeDeltaSlices(dst, a, b *deltaSlice) {
+	ai, bi := a.Iterator(), b.Iterator()
+	x, xok := ai.Next()
+	y, yok := bi.Next()
+	for xok && yok {
+		switch {
+		case x < y:
+			dst.Append(x)
+			x, xok = ai.Next()
+		case y < x:
+			dst.Append(y)
+			y, yok = bi.Next()
+		default:
+			dst.Append(x)
+			x, xok = ai.Next()
+			y, yok = bi.Next()
+		}
+	}
+	for ; xok; x, xok = ai.Next() {
+		dst.Append(x)
+	}
+	for ; yok; y, yok = bi.Next() {
+		dst.Append(y)
+	}
+}
+
+// mergeDeltaIterators appends the sorted union of all iterators to dst, using
+// a min-heap of the iterator heads.
+func mergeDeltaIterators(dst *deltaSlice, iters []*deltaIterator) {
+	heads := make([]deltaHead, 0, len(iters))
+	for _, it := range iters {
+		if x, ok := it.Next(); ok {
+			heads = append(heads, deltaHead{x: x, it: it})
+		}
+	}
+	for i := len(heads)/2 - 1; i >= 0; i-- {
+		siftDeltaHeads(heads, i)
+	}
+
+	for len(heads) != 0 {
+		if x := heads[0].x; dst.Count() == 0 || x != dst.last {
+			dst.Append(x)
+		}
+
+		if x, ok := heads[0].it.Next(); ok {
+			heads[0].x = x
+		} else {
+			heads[0] = heads[len(heads)-1]
+			heads = heads[:len(heads)-1]
+		}
+		siftDeltaHeads(heads, 0)
+	}
+}
+
+type deltaHead struct {
+	x  uint32
+	it *deltaIterator
+}
+
+func siftDeltaHeads(heads []deltaHead, i int) {
+	for {
+		j, l, r := i, 2*i+1, 2*i+2
+		if l < len(heads) && heads[l].x < heads[j].x {
+			j = l
+		}
+		if r < len(heads) && heads[r].x < heads[j].x {
+			j = r
+		}
+		if j == i {
+			return
+		}
+		heads[i], heads[j] = heads[j], heads[i]
+		i = j
+	}
+}
+
+// deltaIterator iterates over the values of a deltaSlice.
+type deltaIterator struct {
+	nums uvarintSlice
+	last uint32
+}
+
+// Next returns the next value and true or false if there are no more values.
+func (it *deltaIterator) Next() (uint32, bool) {
+	u, m := binary.Uvarint(it.nums)
+	if m < 1 {
+		return 0, false
+	}
+	it.nums = it.nums[m:]
+	it.last += uint32(u)
+	return it.last, true
+}
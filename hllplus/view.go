@@ -0,0 +1,129 @@
+package hllplus
+
+import (
+	pb "github.com/bsm/zetasketch/internal/zetasketch"
+)
+
+// View is a read-only view of a serialized sketch. It references the normal
+// or sparse data of the serialized sketch directly, without copying or
+// decoding it. The referenced data must not be modified while the view is in
+// use.
+type View struct {
+	data       []byte
+	sparseData []byte
+
+	precision       uint8
+	sparsePrecision uint8
+	estimator       Estimator
+}
+
+// NewView inits a view of a serialized sketch from its precisions and its
+// normal or sparse data, as stored in HyperLogLogPlusUniqueStateProto. The
+// sparse data takes precedence. Normal data must either be empty or contain
+// exactly one register per bucket.
+func NewView(precision, sparsePrecision uint8, data, sparseData []byte) (*View, error) {
+	v, err := makeView(precision, sparsePrecision, data, sparseData)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func makeView(precision, sparsePrecision uint8, data, sparseData []byte) (View, error) {
+	if err := validate(precision, sparsePrecision); err != nil {
+		return View{}, err
+	}
+
+	v := View{precision: precision, sparsePrecision: sparsePrecision}
+	if len(sparseData) > 0 {
+		v.sparseData = sparseData
+	} else if err := validateNormal(data, precision); err != nil {
+		return View{}, err
+	} else {
+		v.data = data
+	}
+	return v, nil
+}
+
+// NewViewFromProto inits a view from proto message.
+func NewViewFromProto(msg *pb.HyperLogLogPlusUniqueStateProto) (*View, error) {
+	return NewView(uint8(msg.GetPrecisionOrNumBuckets()), uint8(msg.GetSparsePrecisionOrNumBuckets()), msg.Data, msg.SparseData)
+}
+
+// Precision returns the normal precision.
+func (v *View) Precision() uint8 {
+	return v.precision
+}
+
+// SparsePrecision returns the sparse precision.
+func (v *View) SparsePrecision() uint8 {
+	return v.sparsePrecision
+}
+
+// Estimator returns the estimator used for normal registers.
+func (v *View) Estimator() Estimator {
+	return v.estimator
+}
+
+// SetEstimator sets the estimator used for normal registers, see
+// HLL.SetEstimator.
+func (v *View) SetEstimator(e Estimator) {
+	v.estimator = e
+}
+
+// IsSparse returns true if the sketch uses the sparse representation.
+func (v *View) IsSparse() bool {
+	return len(v.sparseData) != 0
+}
+
+// Estimate computes the cardinality estimate, see HLL.Estimate.
+func (v *View) Estimate() int64 {
+	if v.IsSparse() {
+		return estimateSparse(uvarintSlice(v.sparseData).Count(), v.sparsePrecision)
+	}
+	n, _ := v.estimator.estimate(v.data, v.precision)
+	return n
+}
+
+// Iterate calls fn with the position and the value of each non-empty normal
+// register. For sparse sketches, fn may be called multiple times with the same
+// position in which case the register holds the maximum value.
+func (v *View) Iterate(fn func(pos uint32, rhoW uint8)) {
+	if v.IsSparse() {
+		v.sketch().sparse.Iterate(fn)
+		return
+	}
+
+	for pos, rhoW := range v.data {
+		if rhoW != 0 {
+			fn(uint32(pos), rhoW)
+		}
+	}
+}
+
+// sketch returns a sketch which references the data of the view. It must
+// only be used for reading.
+func (v *View) sketch() *HLL {
+	s := &HLL{
+		normal:          v.data,
+		precision:       v.precision,
+		sparsePrecision: v.sparsePrecision,
+		estimator:       v.estimator,
+	}
+	if v.IsSparse() {
+		s.sparse = &sparseState{
+			normalPrecision: v.precision,
+			sparsePrecision: v.sparsePrecision,
+
+			data: &deltaSlice{nums: v.sparseData},
+
+			encodedFlag: sparseEncodedFlag(v.precision, v.sparsePrecision),
+		}
+	}
+	return s
+}
+
+// MergeView merges the sketch of view v into s.
+func (s *HLL) MergeView(v *View) {
+	s.Merge(v.sketch())
+}
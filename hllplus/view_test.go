@@ -0,0 +1,119 @@
+package hllplus_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bsm/zetasketch/hllplus"
+	pb "github.com/bsm/zetasketch/internal/zetasketch"
+	"google.golang.org/protobuf/proto"
+)
+
+// newViewTestProto builds a sketch with n values and returns its serialized
+// state, decoded into a proto message.
+func newViewTestProto(t *testing.T, n int) *pb.HyperLogLogPlusUniqueStateProto {
+	t.Helper()
+
+	msg := new(pb.HyperLogLogPlusUniqueStateProto)
+	if err := proto.Unmarshal(marshalHLL(t, buildHLL(t, 12, 17, n, 33)), msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestView(t *testing.T) {
+	for _, n := range []int{0, 100, 10_000} {
+		msg := newViewTestProto(t, n)
+		orig := marshalHLL(t, mustNewFromProto(t, msg))
+
+		subject, err := hllplus.NewViewFromProto(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, exp := subject.Precision(), uint8(12); got != exp {
+			t.Errorf("n=%d Precision: got %d, want %d", n, got, exp)
+		}
+		if got, exp := subject.SparsePrecision(), uint8(17); got != exp {
+			t.Errorf("n=%d SparsePrecision: got %d, want %d", n, got, exp)
+		}
+		if got, exp := subject.IsSparse(), len(msg.SparseData) != 0; got != exp {
+			t.Errorf("n=%d IsSparse: got %v, want %v", n, got, exp)
+		}
+		if got, exp := subject.Estimate(), mustNewFromProto(t, msg).Estimate(); got != exp {
+			t.Errorf("n=%d Estimate: got %d, want %d", n, got, exp)
+		}
+
+		// registers match the normalized sketch
+		exp, _ := hllplus.NewNormal(12)
+		exp.Merge(mustNewFromProto(t, msg))
+
+		got := make([]byte, 1<<12)
+		subject.Iterate(func(pos uint32, rhoW uint8) {
+			got[pos] = max(got[pos], rhoW)
+		})
+		if !bytes.Equal(got, exp.Proto().GetData()) {
+			t.Errorf("n=%d Iterate: registers do not match", n)
+		}
+
+		// referenced data is not modified
+		if !bytes.Equal(marshalHLL(t, mustNewFromProto(t, msg)), orig) {
+			t.Errorf("n=%d: data was modified", n)
+		}
+	}
+
+	if _, err := hllplus.NewView(9, 17, nil, nil); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := hllplus.NewView(12, 17, make([]byte, 1<<12-1), nil); err == nil {
+		t.Error("truncated data: expected error")
+	}
+	if _, err := hllplus.NewView(12, 17, make([]byte, 1<<13), nil); err == nil {
+		t.Error("oversized data: expected error")
+	}
+	if _, err := hllplus.EstimateData(12, 17, make([]byte, 100), nil); err == nil {
+		t.Error("EstimateData: expected error")
+	}
+
+	msg := newViewTestProto(t, 100_000)
+	msg.Data = msg.Data[:len(msg.Data)/2]
+	if _, err := hllplus.NewFromProto(msg); err == nil {
+		t.Error("NewFromProto: expected error")
+	}
+}
+
+func TestHLL_MergeView(t *testing.T) {
+	for _, n := range []int{0, 100, 10_000} {
+		msg := newViewTestProto(t, n)
+		view, err := hllplus.NewViewFromProto(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, target := range []struct{ n, p, sp int }{
+			{100, 12, 17},
+			{10_000, 12, 17},
+			{100, 11, 16},
+			{100, 14, 19},
+		} {
+			subject := buildHLL(t, uint8(target.p), uint8(target.sp), target.n, 34)
+			exp := buildHLL(t, uint8(target.p), uint8(target.sp), target.n, 34)
+
+			subject.MergeView(view)
+			exp.Merge(mustNewFromProto(t, msg))
+
+			if got, want := marshalHLL(t, subject), marshalHLL(t, exp); !bytes.Equal(got, want) {
+				t.Errorf("n=%d target=%+v: merged state differs", n, target)
+			}
+		}
+	}
+}
+
+func mustNewFromProto(t *testing.T, msg *pb.HyperLogLogPlusUniqueStateProto) *hllplus.HLL {
+	t.Helper()
+
+	s, err := hllplus.NewFromProto(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
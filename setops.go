@@ -0,0 +1,150 @@
+package zetasketch
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/bsm/zetasketch/hllplus"
+)
+
+// MaxIntersectionSketches is the maximum number of aggregators accepted by
+// IntersectionEstimateN. The inclusion–exclusion formula requires the
+// cardinalities of 2^n - 1 unions.
+const MaxIntersectionSketches = 16
+
+// IntersectionEstimate estimates the number of unique values seen by both a
+// and b, together with its error bounds at the given confidence level, see
+// HLL.ResultBounds. The aggregators are not modified and may be shared by
+// concurrent calls.
+//
+// The estimate is computed by inclusion–exclusion as |A| + |B| - |A ∪ B|.
+// Its absolute error depends on the size of the union rather than on the size
+// of the intersection, so small intersections of large sets are imprecise. The
+// standard error is the sum of the standard errors of all terms, which bounds
+// the error regardless of their correlation. Estimates and bounds are never
+// negative and never exceed the cardinality of the smaller set.
+func IntersectionEstimate(a, b *HLL, confidence float64) (Bounds, error) {
+	return IntersectionEstimateN([]*HLL{a, b}, confidence)
+}
+
+// IntersectionEstimateN estimates the number of unique values seen by all
+// aggregators by inclusion–exclusion over all their unions, see
+// IntersectionEstimate. At most MaxIntersectionSketches aggregators are
+// accepted.
+func IntersectionEstimateN(hlls []*HLL, confidence float64) (Bounds, error) {
+	if len(hlls) == 0 || len(hlls) > MaxIntersectionSketches {
+		return Bounds{}, fmt.Errorf("cannot intersect %d aggregators, must be between 1 and %d", len(hlls), MaxIntersectionSketches)
+	}
+
+	var (
+		est, stdErr float64
+		limit       Bounds
+	)
+
+	// Visit all non-empty subsets depth-first, so only one union per level is
+	// kept at a time. Unions of an odd number of sets are added, all others
+	// are subtracted.
+	var visit func(i int, union *HLL, odd bool) error
+	visit = func(i int, union *HLL, odd bool) error {
+		for j := i; j < len(hlls); j++ {
+			next := hlls[j]
+			if union != nil {
+				next = union.Clone()
+				if err := next.Merge(hlls[j]); err != nil {
+					return err
+				}
+			}
+
+			b, err := next.ResultBounds(confidence)
+			if err != nil {
+				return err
+			}
+			if union == nil && (j == 0 || b.Estimate < limit.Estimate) {
+				limit = b
+			}
+
+			if odd {
+				est += float64(b.Estimate)
+			} else {
+				est -= float64(b.Estimate)
+			}
+			stdErr += b.StdError
+
+			if err := visit(j+1, next, !odd); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := visit(0, nil, true); err != nil {
+		return Bounds{}, err
+	}
+
+	return clampedBounds(est, stdErr, limit, confidence), nil
+}
+
+// DifferenceEstimate estimates the number of unique values seen by a but not
+// by b, together with its error bounds at the given confidence level, see
+// HLL.ResultBounds. The aggregators are not modified and may be shared by
+// concurrent calls.
+//
+// The estimate is computed as |A ∪ B| - |B|. Like with IntersectionEstimate,
+// the standard error is the sum of the standard errors of both terms and
+// estimates and bounds are never negative and never exceed the cardinality of
+// a.
+func DifferenceEstimate(a, b *HLL, confidence float64) (Bounds, error) {
+	union := a.Clone()
+	if err := union.Merge(b); err != nil {
+		return Bounds{}, err
+	}
+
+	ub, err := union.ResultBounds(confidence)
+	if err != nil {
+		return Bounds{}, err
+	}
+	bb, err := b.ResultBounds(confidence)
+	if err != nil {
+		return Bounds{}, err
+	}
+	ab, err := a.ResultBounds(confidence)
+	if err != nil {
+		return Bounds{}, err
+	}
+
+	est := float64(ub.Estimate - bb.Estimate)
+	return clampedBounds(est, ub.StdError+bb.StdError, ab, confidence), nil
+}
+
+// Jaccard is an estimate of the Jaccard similarity of two sets.
+type Jaccard = hllplus.Jaccard
+
+// Similarity estimates the Jaccard similarity |A ∩ B| / |A ∪ B| of the unique
+// values seen by a and b, together with its standard error. The aggregators
+// are not modified. Aggregators with different precisions are compared at
+// their lowest common precisions, aggregators with incompatible value types
+// cannot be compared.
+//
+// While both aggregators are sparse, the similarity is computed from the
+// sparse representations directly and is exact up to hash collisions. The
+// error is then the expected overestimate caused by collisions, see Jaccard.
+// Otherwise, it is derived by inclusion–exclusion, see IntersectionEstimate.
+func Similarity(a, b *HLL) (Jaccard, error) {
+	if _, err := mergeValueTypes(a.t, b.t); err != nil {
+		return Jaccard{}, fmt.Errorf("cannot compare %T: %w", b, err)
+	}
+	return hllplus.Similarity(a.h, b.h), nil
+}
+
+// clampedBounds returns the bounds of estimate est with standard error stdErr,
+// clamped between zero and the bounds of a limiting set.
+func clampedBounds(est, stdErr float64, limit Bounds, confidence float64) Bounds {
+	z := math.Sqrt2 * math.Erfinv(confidence)
+	d := z * stdErr
+
+	return Bounds{
+		Estimate: min(max(0, int64(math.Round(est))), limit.Estimate),
+		StdError: stdErr,
+		Lower:    min(max(0, int64(math.Floor(est-d))), limit.Upper),
+		Upper:    min(max(0, int64(math.Ceil(est+d))), limit.Upper),
+	}
+}
@@ -0,0 +1,200 @@
+package zetasketch_test
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/bsm/zetasketch"
+)
+
+func newRangeHLL(min, max int) *zetasketch.HLL {
+	h := zetasketch.NewHLL(nil)
+	for i := min; i < max; i++ {
+		h.Add(zetasketch.Uint64Value(uint64(i)))
+	}
+	return h
+}
+
+// assertSetBounds checks that b is valid and contains the actual cardinality.
+func assertSetBounds(t *testing.T, name string, b zetasketch.Bounds, actual int64) {
+	t.Helper()
+
+	if b.Lower < 0 || b.Lower > b.Estimate || b.Estimate > b.Upper {
+		t.Errorf("%s: invalid bounds %+v", name, b)
+	}
+	if actual < b.Lower || actual > b.Upper {
+		t.Errorf("%s: got %+v, expected to contain %d", name, b, actual)
+	}
+}
+
+func TestIntersectionEstimate(t *testing.T) {
+	a, b := newRangeHLL(0, 10_000), newRangeHLL(5_000, 15_000)
+	res, err := zetasketch.IntersectionEstimate(a, b, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := res.Estimate, int64(5_000); got != exp {
+		t.Errorf("got %d, want %d", got, exp)
+	}
+	assertSetBounds(t, "overlapping", res, 5_000)
+
+	// inputs are not modified:
+	if got, exp := a.NumValues(), int64(10_000); got != exp {
+		t.Errorf("NumValues: got %d, want %d", got, exp)
+	}
+
+	// disjoint sets
+	res, err = zetasketch.IntersectionEstimate(newRangeHLL(0, 10_000), newRangeHLL(10_000, 20_000), 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSetBounds(t, "disjoint", res, 0)
+
+	// subset
+	res, err = zetasketch.IntersectionEstimate(newRangeHLL(0, 10_000), newRangeHLL(0, 100), 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, max := res.Estimate, int64(100); got > max {
+		t.Errorf("got %d, want <= %d", got, max)
+	}
+	assertSetBounds(t, "subset", res, 100)
+}
+
+func TestIntersectionEstimateN(t *testing.T) {
+	hlls := []*zetasketch.HLL{newRangeHLL(0, 6_000), newRangeHLL(2_000, 8_000), newRangeHLL(4_000, 10_000)}
+	res, err := zetasketch.IntersectionEstimateN(hlls, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := res.Estimate, int64(2_007); got != exp {
+		t.Errorf("got %d, want %d", got, exp)
+	}
+	assertSetBounds(t, "3-way", res, 2_000)
+
+	// single set
+	res, err = zetasketch.IntersectionEstimateN(hlls[:1], 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := res.Estimate, hlls[0].Result(); got != exp {
+		t.Errorf("got %d, want %d", got, exp)
+	}
+
+	if _, err := zetasketch.IntersectionEstimateN(nil, 0.95); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := zetasketch.IntersectionEstimateN(make([]*zetasketch.HLL, zetasketch.MaxIntersectionSketches+1), 0.95); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := zetasketch.IntersectionEstimateN(hlls, 1.5); err == nil {
+		t.Error("expected error")
+	}
+
+	strs := zetasketch.NewHLL(nil)
+	strs.Add(zetasketch.StringValue("foo"))
+	if _, err := zetasketch.IntersectionEstimateN(append(hlls, strs), 0.95); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestDifferenceEstimate(t *testing.T) {
+	a, b := newRangeHLL(0, 10_000), newRangeHLL(5_000, 15_000)
+	res, err := zetasketch.DifferenceEstimate(a, b, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := res.Estimate, int64(4_993); got != exp {
+		t.Errorf("got %d, want %d", got, exp)
+	}
+	assertSetBounds(t, "overlapping", res, 5_000)
+
+	// subset
+	res, err = zetasketch.DifferenceEstimate(newRangeHLL(0, 100), newRangeHLL(0, 10_000), 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSetBounds(t, "subset", res, 0)
+
+	if _, err := zetasketch.DifferenceEstimate(a, b, 0); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	a, b := newRangeHLL(0, 1_000), newRangeHLL(500, 1_500)
+	res, err := zetasketch.Similarity(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := res.Similarity, 1.0/3; math.Abs(got-exp) > 0.001 {
+		t.Errorf("got %.4f, want %.4f", got, exp)
+	}
+	if res.StdError > 0.001 {
+		t.Errorf("StdError: got %v", res.StdError)
+	}
+
+	// mixed precisions and normal representation
+	c := zetasketch.NewHLL(&zetasketch.HLLConfig{Precision: 12})
+	for i := 500; i < 50_500; i++ {
+		c.Add(zetasketch.Uint64Value(uint64(i)))
+	}
+	d := newRangeHLL(0, 50_000)
+	res, err = zetasketch.Similarity(c, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := res.Similarity, 49_500.0/50_500; math.Abs(got-exp) > 3*res.StdError {
+		t.Errorf("got %.4f±%.4f, want %.4f", got, res.StdError, exp)
+	}
+
+	strs := zetasketch.NewHLL(nil)
+	strs.Add(zetasketch.StringValue("foo"))
+	if _, err := zetasketch.Similarity(a, strs); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestSetOps_concurrent(t *testing.T) {
+	for range 10 {
+		// a is sparse with buffered values, b is normal
+		a, b := newRangeHLL(0, 1_000), newRangeHLL(500, 100_000)
+
+		expI, err := zetasketch.IntersectionEstimate(a.Clone(), b.Clone(), 0.95)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expD, err := zetasketch.DifferenceEstimate(a.Clone(), b.Clone(), 0.95)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Go(func() {
+				x, y := a, b
+				if i%2 == 1 {
+					x, y = b, a
+				}
+				if got, err := zetasketch.IntersectionEstimate(a, b, 0.95); err != nil {
+					t.Error(err)
+				} else if got != expI {
+					t.Errorf("IntersectionEstimate: got %+v, want %+v", got, expI)
+				}
+				if _, err := zetasketch.IntersectionEstimate(x, y, 0.95); err != nil {
+					t.Error(err)
+				}
+				if got, err := zetasketch.DifferenceEstimate(a, b, 0.95); err != nil {
+					t.Error(err)
+				} else if got != expD {
+					t.Errorf("DifferenceEstimate: got %+v, want %+v", got, expD)
+				}
+				if _, err := zetasketch.DifferenceEstimate(x, y, 0.95); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+		wg.Wait()
+	}
+}
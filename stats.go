@@ -0,0 +1,71 @@
+package zetasketch
+
+import (
+	"fmt"
+
+	pb "github.com/bsm/zetasketch/internal/zetasketch"
+	"google.golang.org/protobuf/proto"
+)
+
+// HLLStats summarizes the result of a HLL++ aggregator. It serializes to a
+// BigQuery/zetasketch compatible AggregatorStatsProto message.
+type HLLStats struct {
+	// NumValues is the total number of values added to the aggregator.
+	NumValues int64
+	// EstimatedCardinality is the estimated number of unique values.
+	EstimatedCardinality int64
+	// ExpectedError is the relative standard error of the estimate.
+	ExpectedError float64
+}
+
+// Stats returns a summary of the aggregator.
+func (h *HLL) Stats() *HLLStats {
+	b, _ := h.ResultBounds(0.5) // confidence is irrelevant for the standard error
+	return &HLLStats{
+		NumValues:            h.n,
+		EstimatedCardinality: b.Estimate,
+		ExpectedError:        b.RelativeError(),
+	}
+}
+
+// MarshalBinary serializes stats to bytes.
+func (s *HLLStats) MarshalBinary() ([]byte, error) {
+	return proto.Marshal(s.proto())
+}
+
+// UnmarshalBinary deserializes stats from bytes.
+func (s *HLLStats) UnmarshalBinary(data []byte) error {
+	msg := new(pb.AggregatorStatsProto)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return err
+	}
+	return s.fromProto(msg)
+}
+
+func (s *HLLStats) proto() *pb.AggregatorStatsProto {
+	msg := &pb.AggregatorStatsProto{
+		NumValues: proto.Int64(s.NumValues),
+	}
+	proto.SetExtension(msg, pb.E_HyperloglogPlusUniqueStats, &pb.UniqueStatsProto{
+		EstimatedCardinality: proto.Int64(s.EstimatedCardinality),
+		ExpectedError:        proto.Float64(s.ExpectedError),
+	})
+	return msg
+}
+
+func (s *HLLStats) fromProto(msg *pb.AggregatorStatsProto) error {
+	if !proto.HasExtension(msg, pb.E_HyperloglogPlusUniqueStats) {
+		return fmt.Errorf("incompatible binary message: no HyperLogLog++ stats")
+	}
+
+	ext := proto.GetExtension(msg, pb.E_HyperloglogPlusUniqueStats)
+	stats, ok := ext.(*pb.UniqueStatsProto)
+	if !ok {
+		return fmt.Errorf("incompatible binary message: invalid HyperLogLog++ stats")
+	}
+
+	s.NumValues = msg.GetNumValues()
+	s.EstimatedCardinality = stats.GetEstimatedCardinality()
+	s.ExpectedError = stats.GetExpectedError()
+	return nil
+}
@@ -0,0 +1,60 @@
+package zetasketch_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bsm/zetasketch"
+	pb "github.com/bsm/zetasketch/internal/zetasketch"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestHLL_Stats(t *testing.T) {
+	subject := newTestHLL()
+	stats := subject.Stats()
+
+	if got, exp := stats.NumValues, int64(1_500); got != exp {
+		t.Errorf("NumValues: got %d, want %d", got, exp)
+	}
+	if got, exp := stats.EstimatedCardinality, int64(1_000); got != exp {
+		t.Errorf("EstimatedCardinality: got %d, want %d", got, exp)
+	}
+	if got, exp := stats.ExpectedError, 0.00069; math.Abs(got-exp) > 0.00001 {
+		t.Errorf("ExpectedError: got %v, want %v", got, exp)
+	}
+}
+
+func TestHLLStats_MarshalBinary(t *testing.T) {
+	subject := newTestHLL().Stats()
+
+	data, err := subject.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := new(pb.AggregatorStatsProto)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := msg.GetNumValues(), int64(1_500); got != exp {
+		t.Errorf("NumValues: got %d, want %d", got, exp)
+	}
+	ext := proto.GetExtension(msg, pb.E_HyperloglogPlusUniqueStats).(*pb.UniqueStatsProto)
+	if got, exp := ext.GetEstimatedCardinality(), int64(1_000); got != exp {
+		t.Errorf("EstimatedCardinality: got %d, want %d", got, exp)
+	}
+
+	restored := new(zetasketch.HLLStats)
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if *restored != *subject {
+		t.Errorf("got %+v, want %+v", restored, subject)
+	}
+
+	// stats without HLL++ extension are rejected:
+	data, _ = proto.Marshal(&pb.AggregatorStatsProto{NumValues: proto.Int64(1)})
+	if err := restored.UnmarshalBinary(data); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,241 @@
+package zetasketch
+
+import (
+	"fmt"
+	"math"
+
+	pb "github.com/bsm/zetasketch/internal/zetasketch"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// sumStateField is the AggregatorStateProto extension field which holds the
+// state of a SUM aggregator.
+const sumStateField = protowire.Number(pb.AggregatorType_SUM)
+
+// Sum implements an aggregator which computes the exact sum of numeric values.
+// Supported value types are ValueTypeInt64, ValueTypeUint64 and ValueTypeDouble.
+// Integer sums wrap around on overflow. The zero value is an empty sum of
+// int64 values.
+//
+// Serialized sums use the SUM aggregator type, but aggregator.proto does not
+// declare a state for it. The sum is stored in field 100 of
+// AggregatorStateProto, a wire format which is specific to this package and
+// cannot be read by the Java library or BigQuery.
+//
+// Note that this aggregator is not designed to be thread safe.
+type Sum struct {
+	t ValueType
+	s uint64 // raw bits of the sum, interpreted according to t
+	n int64
+}
+
+// NewInt64Sum inits a new SUM aggregator over int64 values.
+func NewInt64Sum() *Sum {
+	return &Sum{t: ValueTypeInt64}
+}
+
+// NewUint64Sum inits a new SUM aggregator over uint64 values.
+func NewUint64Sum() *Sum {
+	return &Sum{t: ValueTypeUint64}
+}
+
+// NewDoubleSum inits a new SUM aggregator over float64 values.
+func NewDoubleSum() *Sum {
+	return &Sum{t: ValueTypeDouble}
+}
+
+// ValueType returns the type of the summed values.
+func (s *Sum) ValueType() ValueType {
+	if s.t == ValueTypeUnknown {
+		return ValueTypeInt64
+	}
+	return s.t
+}
+
+// Add adds v to the aggregator, converting it to the aggregator's value type.
+// See AddUint64 and AddFloat64 for other numeric types.
+func (s *Sum) Add(v int64) {
+	if s.t == ValueTypeDouble {
+		s.add(math.Float64bits(float64(v)))
+	} else {
+		s.add(uint64(v))
+	}
+}
+
+// AddUint64 adds v to the aggregator, converting it to the aggregator's value type.
+func (s *Sum) AddUint64(v uint64) {
+	if s.t == ValueTypeDouble {
+		s.add(math.Float64bits(float64(v)))
+	} else {
+		s.add(v)
+	}
+}
+
+// AddFloat64 adds v to the aggregator, converting it to the aggregator's value type.
+func (s *Sum) AddFloat64(v float64) {
+	switch s.t {
+	case ValueTypeDouble:
+		s.add(math.Float64bits(v))
+	case ValueTypeUint64:
+		s.add(uint64(v))
+	default:
+		s.add(uint64(int64(v)))
+	}
+}
+
+// NumValues returns the number of values seen.
+func (s *Sum) NumValues() int64 {
+	return s.n
+}
+
+// Merge merges aggregator other into s.
+// Only sums of the same value type can be merged.
+func (s *Sum) Merge(other Aggregator) error {
+	s2, ok := other.(*Sum)
+	if !ok {
+		return fmt.Errorf("cannot merge %T into %T", other, s)
+	}
+	if t, t2 := s.ValueType(), s2.ValueType(); t != t2 {
+		return fmt.Errorf("cannot merge %s sum into %s sum", t2, t)
+	}
+
+	s.accumulate(s2.s)
+	s.n += s2.n
+	return nil
+}
+
+// Int64 returns the sum as an int64.
+func (s *Sum) Int64() int64 {
+	if s.t == ValueTypeDouble {
+		return int64(s.Float64())
+	}
+	return int64(s.s)
+}
+
+// Uint64 returns the sum as an uint64.
+func (s *Sum) Uint64() uint64 {
+	if s.t == ValueTypeDouble {
+		return uint64(s.Float64())
+	}
+	return s.s
+}
+
+// Float64 returns the sum as a float64.
+func (s *Sum) Float64() float64 {
+	switch s.t {
+	case ValueTypeDouble:
+		return math.Float64frombits(s.s)
+	case ValueTypeUint64:
+		return float64(s.s)
+	default:
+		return float64(int64(s.s))
+	}
+}
+
+// MarshalBinary serializes aggregator to bytes.
+func (s *Sum) MarshalBinary() ([]byte, error) {
+	return proto.Marshal(s.proto())
+}
+
+// UnmarshalBinary deserializes aggregator from bytes.
+func (s *Sum) UnmarshalBinary(data []byte) error {
+	msg := new(pb.AggregatorStateProto)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return err
+	}
+	return s.fromProto(msg)
+}
+
+func (s *Sum) add(v uint64) {
+	s.n++
+	s.accumulate(v)
+}
+
+func (s *Sum) accumulate(v uint64) {
+	if s.t == ValueTypeDouble {
+		s.s = math.Float64bits(math.Float64frombits(s.s) + math.Float64frombits(v))
+	} else {
+		s.s += v
+	}
+}
+
+func (s *Sum) proto() *pb.AggregatorStateProto {
+	var (
+		aggType   = pb.AggregatorType_SUM
+		numValues = s.n
+		valueType = int32(s.ValueType())
+	)
+	msg := &pb.AggregatorStateProto{
+		Type:      &aggType,
+		NumValues: &numValues,
+		ValueType: &valueType,
+	}
+
+	// The sum is stored in the (otherwise undeclared) SUM extension field,
+	// integers as varints and doubles as fixed64.
+	var state []byte
+	if s.t == ValueTypeDouble {
+		state = protowire.AppendTag(state, sumStateField, protowire.Fixed64Type)
+		state = protowire.AppendFixed64(state, s.s)
+	} else {
+		state = protowire.AppendTag(state, sumStateField, protowire.VarintType)
+		state = protowire.AppendVarint(state, s.s)
+	}
+	msg.ProtoReflect().SetUnknown(state)
+	return msg
+}
+
+func (s *Sum) fromProto(msg *pb.AggregatorStateProto) error {
+	if msg.GetType() != pb.AggregatorType_SUM {
+		return fmt.Errorf("incompatible binary message: unexpected type %s", msg.GetType().String())
+	}
+	if msg.NumValues == nil {
+		return fmt.Errorf("incompatible binary message: no num values")
+	}
+
+	valueType := ValueType(msg.GetValueType())
+	expectedWireType := protowire.VarintType
+	switch valueType {
+	case ValueTypeInt64, ValueTypeUint64:
+	case ValueTypeDouble:
+		expectedWireType = protowire.Fixed64Type
+	default:
+		return fmt.Errorf("incompatible binary message: unsupported value type %s", valueType)
+	}
+
+	var (
+		sum   uint64
+		found bool
+	)
+	for b := []byte(msg.ProtoReflect().GetUnknown()); len(b) > 0; {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if num == sumStateField && typ == expectedWireType {
+			if typ == protowire.Fixed64Type {
+				sum, n = protowire.ConsumeFixed64(b)
+			} else {
+				sum, n = protowire.ConsumeVarint(b)
+			}
+			found = true
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	if !found {
+		return fmt.Errorf("incompatible binary message: no sum")
+	}
+
+	s.t = valueType
+	s.s = sum
+	s.n = msg.GetNumValues()
+	return nil
+}
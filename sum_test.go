@@ -0,0 +1,153 @@
+package zetasketch_test
+
+import (
+	"testing"
+
+	"github.com/bsm/zetasketch"
+	pb "github.com/bsm/zetasketch/internal/zetasketch"
+	"google.golang.org/protobuf/proto"
+)
+
+var _ zetasketch.Aggregator = (*zetasketch.Sum)(nil)
+
+func TestSum_Int64(t *testing.T) {
+	subject := zetasketch.NewInt64Sum()
+	for i := range 1_000 {
+		subject.Add(int64(i) - 600)
+	}
+	subject.AddFloat64(-2.7)
+
+	if got, exp := subject.NumValues(), int64(1_001); got != exp {
+		t.Errorf("NumValues: got %d, want %d", got, exp)
+	}
+	if got, exp := subject.Int64(), int64(-100_502); got != exp {
+		t.Errorf("Int64: got %d, want %d", got, exp)
+	}
+	if got, exp := subject.Float64(), -100_502.0; got != exp {
+		t.Errorf("Float64: got %v, want %v", got, exp)
+	}
+}
+
+func TestSum_Uint64(t *testing.T) {
+	subject := zetasketch.NewUint64Sum()
+	subject.AddUint64(1 << 63)
+	subject.AddUint64(1<<63 + 5) // wraps around
+	subject.Add(3)
+
+	if got, exp := subject.Uint64(), uint64(8); got != exp {
+		t.Errorf("Uint64: got %d, want %d", got, exp)
+	}
+}
+
+func TestSum_Double(t *testing.T) {
+	subject := zetasketch.NewDoubleSum()
+	subject.AddFloat64(0.25)
+	subject.Add(-2)
+	subject.AddUint64(4)
+
+	if got, exp := subject.Float64(), 2.25; got != exp {
+		t.Errorf("Float64: got %v, want %v", got, exp)
+	}
+	if got, exp := subject.Int64(), int64(2); got != exp {
+		t.Errorf("Int64: got %d, want %d", got, exp)
+	}
+}
+
+func TestSum_zeroValue(t *testing.T) {
+	var subject zetasketch.Sum
+	subject.Add(4)
+
+	if got, exp := subject.ValueType(), zetasketch.ValueTypeInt64; got != exp {
+		t.Errorf("ValueType: got %s, want %s", got, exp)
+	}
+	if err := subject.Merge(zetasketch.NewInt64Sum()); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+
+	data, err := subject.MarshalBinary()
+	if err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	restored := new(zetasketch.Sum)
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal("expected no error, got", err)
+	}
+	if got, exp := restored.Int64(), int64(4); got != exp {
+		t.Errorf("Int64: got %d, want %d", got, exp)
+	}
+	if got, exp := restored.ValueType(), zetasketch.ValueTypeInt64; got != exp {
+		t.Errorf("ValueType: got %s, want %s", got, exp)
+	}
+}
+
+func TestSum_Merge(t *testing.T) {
+	subject := zetasketch.NewInt64Sum()
+	subject.Add(5)
+
+	other := zetasketch.NewInt64Sum()
+	other.Add(7)
+	other.Add(-3)
+
+	if err := subject.Merge(other); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := subject.NumValues(), int64(3); got != exp {
+		t.Errorf("NumValues: got %d, want %d", got, exp)
+	}
+	if got, exp := subject.Int64(), int64(9); got != exp {
+		t.Errorf("Int64: got %d, want %d", got, exp)
+	}
+
+	// different value types cannot be merged:
+	if err := subject.Merge(zetasketch.NewDoubleSum()); err == nil {
+		t.Error("expected error")
+	}
+	if err := subject.Merge(zetasketch.NewHLL(nil)); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestSum_MarshalBinary(t *testing.T) {
+	cases := []struct {
+		subject   *zetasketch.Sum
+		valueType pb.DefaultOpsType_Id
+	}{
+		{zetasketch.NewInt64Sum(), pb.DefaultOpsType_INT64},
+		{zetasketch.NewUint64Sum(), pb.DefaultOpsType_UINT64},
+		{zetasketch.NewDoubleSum(), pb.DefaultOpsType_DOUBLE},
+	}
+	for _, tc := range cases {
+		tc.subject.Add(-8)
+		tc.subject.AddFloat64(1.5)
+
+		data, err := tc.subject.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		msg := new(pb.AggregatorStateProto)
+		if err := proto.Unmarshal(data, msg); err != nil {
+			t.Fatal(err)
+		}
+		if got, exp := msg.GetType(), pb.AggregatorType_SUM; got != exp {
+			t.Errorf("Type: got %s, want %s", got, exp)
+		}
+		if got, exp := msg.GetValueType(), int32(tc.valueType); got != exp {
+			t.Errorf("ValueType: got %d, want %d", got, exp)
+		}
+
+		restored := new(zetasketch.Sum)
+		if err := restored.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if got, exp := restored.ValueType(), tc.subject.ValueType(); got != exp {
+			t.Errorf("ValueType: got %s, want %s", got, exp)
+		}
+		if got, exp := restored.NumValues(), int64(2); got != exp {
+			t.Errorf("NumValues: got %d, want %d", got, exp)
+		}
+		if got, exp := restored.Float64(), tc.subject.Float64(); got != exp {
+			t.Errorf("Float64: got %v, want %v", got, exp)
+		}
+	}
+}
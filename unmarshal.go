@@ -0,0 +1,95 @@
+package zetasketch
+
+import (
+	"fmt"
+	"sync"
+
+	pb "github.com/bsm/zetasketch/internal/zetasketch"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// AggregatorType identifies the type of a serialized aggregator. It
+// corresponds to the AggregatorType enum of the serialized aggregator state.
+type AggregatorType int32
+
+// Known aggregator types.
+const (
+	AggregatorTypeSum = AggregatorType(pb.AggregatorType_SUM)
+	AggregatorTypeHLL = AggregatorType(pb.AggregatorType_HYPERLOGLOG_PLUS_UNIQUE)
+)
+
+// String returns the name of the aggregator type.
+func (t AggregatorType) String() string {
+	return pb.AggregatorType(t).String()
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[AggregatorType]func() Aggregator)
+)
+
+func init() {
+	Register(AggregatorTypeSum, func() Aggregator { return new(Sum) })
+	Register(AggregatorTypeHLL, func() Aggregator { return new(HLL) })
+}
+
+// Register makes an aggregator type available to Unmarshal. The factory must
+// return an empty aggregator which is then populated via UnmarshalBinary.
+// Register panics if it is called twice for the same type or if factory is nil.
+func Register(t AggregatorType, factory func() Aggregator) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("zetasketch: Register factory is nil")
+	}
+	if _, dup := registry[t]; dup {
+		panic(fmt.Sprintf("zetasketch: Register called twice for %s", t))
+	}
+	registry[t] = factory
+}
+
+// Unmarshal deserializes an aggregator of any registered type from bytes.
+func Unmarshal(data []byte) (Aggregator, error) {
+	t, err := aggregatorTypeOf(data)
+	if err != nil {
+		return nil, err
+	}
+
+	registryMu.RLock()
+	factory, ok := registry[t]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("incompatible binary message: unsupported type %s", t)
+	}
+
+	agg := factory()
+	if err := agg.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return agg, nil
+}
+
+// aggregatorTypeOf extracts the type field of a serialized AggregatorStateProto
+// without decoding the full message.
+func aggregatorTypeOf(data []byte) (AggregatorType, error) {
+	var (
+		t     AggregatorType
+		found bool
+	)
+	err := walkFields(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num == stateTypeField && typ == protowire.VarintType {
+			v, n := protowire.ConsumeVarint(b)
+			t, found = AggregatorType(int32(v)), true
+			return n, nil
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, fmt.Errorf("incompatible binary message: no type")
+	}
+	return t, nil
+}
@@ -0,0 +1,122 @@
+package zetasketch_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bsm/zetasketch"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestUnmarshal(t *testing.T) {
+	hll := newTestHLL()
+	sum := zetasketch.NewInt64Sum()
+	sum.Add(42)
+
+	for _, agg := range []zetasketch.Aggregator{hll, sum} {
+		data, err := agg.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := zetasketch.Unmarshal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.NumValues() != agg.NumValues() {
+			t.Errorf("NumValues: got %d, want %d", got.NumValues(), agg.NumValues())
+		}
+
+		switch v := got.(type) {
+		case *zetasketch.HLL:
+			if got, exp := v.Result(), hll.Result(); got != exp {
+				t.Errorf("Result: got %d, want %d", got, exp)
+			}
+		case *zetasketch.Sum:
+			if got, exp := v.Int64(), sum.Int64(); got != exp {
+				t.Errorf("Int64: got %d, want %d", got, exp)
+			}
+		default:
+			t.Errorf("unexpected type %T", got)
+		}
+	}
+}
+
+func TestUnmarshal_invalid(t *testing.T) {
+	for _, data := range [][]byte{
+		nil,                // no type
+		{0x08, 0x65},       // unregistered type 101
+		{0x08, 0x70, 0x10}, // truncated
+	} {
+		if _, err := zetasketch.Unmarshal(data); err == nil {
+			t.Errorf("Unmarshal(%x): expected error", data)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	zetasketch.Register(zetasketch.AggregatorTypeHLL, func() zetasketch.Aggregator { return new(zetasketch.HLL) })
+}
+
+// counter is a custom aggregator, which only counts values.
+type counter struct{ n int64 }
+
+const counterType zetasketch.AggregatorType = 102
+
+func init() {
+	zetasketch.Register(counterType, func() zetasketch.Aggregator { return new(counter) })
+}
+
+func (c *counter) NumValues() int64 { return c.n }
+
+func (c *counter) Merge(other zetasketch.Aggregator) error {
+	c.n += other.NumValues()
+	return nil
+}
+
+func (c *counter) MarshalBinary() ([]byte, error) {
+	b := protowire.AppendTag(nil, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(counterType))
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(c.n)), nil
+}
+
+func (c *counter) UnmarshalBinary(data []byte) error {
+	for len(data) != 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 || typ != protowire.VarintType {
+			return fmt.Errorf("invalid counter")
+		}
+		v, m := protowire.ConsumeVarint(data[n:])
+		if m < 0 {
+			return protowire.ParseError(m)
+		}
+		if num == 2 {
+			c.n = int64(v)
+		}
+		data = data[n+m:]
+	}
+	return nil
+}
+
+func TestRegister_custom(t *testing.T) {
+	data, err := (&counter{n: 7}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := zetasketch.Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := got.(*counter); !ok {
+		t.Errorf("unexpected type %T", got)
+	} else if c.n != 7 {
+		t.Errorf("got %d, want 7", c.n)
+	}
+}
@@ -4,15 +4,17 @@ package zetasketch
 
 import (
 	"encoding"
+	"fmt"
 
-	"github.com/bsm/zetasketch/internal/hash"
+	"github.com/bsm/zetasketch/hash"
+	pb "github.com/bsm/zetasketch/internal/zetasketch"
 )
 
 // Aggregator provides an interface that wraps
-// distributed, online aggregation algorithm.
+// distributed, online aggregation algorithm. The type of the values added to
+// an aggregator depends on the implementation, e.g. HLL.Add accepts hashed
+// values while Sum.Add accepts plain numbers.
 type Aggregator interface {
-	// Add adds a value.
-	Add(v Value)
 	// NumValues returns the total number of input values that this aggregator has seen.
 	NumValues() int64
 	// Merge merges two aggregators.
@@ -22,31 +24,149 @@ type Aggregator interface {
 	encoding.BinaryUnmarshaler
 }
 
+// ValueType identifies the type of values an aggregator operates on. It
+// corresponds to the DefaultOpsType.Id enum of the serialized aggregator state.
+// Custom value types, as used by third-party implementations, are greater than
+// ValueTypeCustom.
+type ValueType int32
+
+// Supported value types.
+const (
+	ValueTypeUnknown = ValueType(pb.DefaultOpsType_UNKNOWN)
+	ValueTypeInt8    = ValueType(pb.DefaultOpsType_INT8)
+	ValueTypeInt16   = ValueType(pb.DefaultOpsType_INT16)
+	ValueTypeInt32   = ValueType(pb.DefaultOpsType_INT32)
+	ValueTypeInt64   = ValueType(pb.DefaultOpsType_INT64)
+	ValueTypeUint8   = ValueType(pb.DefaultOpsType_UINT8)
+	ValueTypeUint16  = ValueType(pb.DefaultOpsType_UINT16)
+	ValueTypeUint32  = ValueType(pb.DefaultOpsType_UINT32)
+	ValueTypeUint64  = ValueType(pb.DefaultOpsType_UINT64)
+	ValueTypeFloat   = ValueType(pb.DefaultOpsType_FLOAT)
+	ValueTypeDouble  = ValueType(pb.DefaultOpsType_DOUBLE)
+	ValueTypeBytes   = ValueType(pb.DefaultOpsType_BYTES_OR_UTF8_STRING)
+
+	// ValueTypeCustom is the lower, exclusive bound of custom value types.
+	ValueTypeCustom ValueType = 1000
+)
+
+// String returns the name of the value type.
+func (t ValueType) String() string {
+	return pb.DefaultOpsType_Id(t).String()
+}
+
+// isValid reports whether t is a known or a custom value type.
+func (t ValueType) isValid() bool {
+	_, ok := pb.DefaultOpsType_Id_name[int32(t)]
+	return ok || t > ValueTypeCustom
+}
+
+// valueTypeMixed marks aggregators which have seen values of different types.
+// Such aggregators cannot be merged and are serialized with an unknown type.
+const valueTypeMixed ValueType = -1
+
+// mergeValueTypes returns the value type of two merged aggregators. Unknown
+// value types are compatible with any other type. INT64 and UINT64 values are
+// hashed identically, so they are compatible and merge into INT64, as used by
+// the Java library and BigQuery.
+func mergeValueTypes(a, b ValueType) (ValueType, error) {
+	switch {
+	case a == valueTypeMixed, b == valueTypeMixed:
+		return valueTypeMixed, fmt.Errorf("mixed value types")
+	case a == b, b == ValueTypeUnknown:
+		return a, nil
+	case a == ValueTypeUnknown:
+		return b, nil
+	case a == ValueTypeInt64 && b == ValueTypeUint64, a == ValueTypeUint64 && b == ValueTypeInt64:
+		return ValueTypeInt64, nil
+	}
+	return a, fmt.Errorf("incompatible value types %s and %s", a, b)
+}
+
 // Value is a hashable value.
 type Value interface {
 	Sum64() uint64
 }
 
-type hashSum uint64
+// TypedValue is a Value which is aware of its ValueType.
+// All values returned by the constructors of this package are typed.
+type TypedValue interface {
+	Value
+	ValueType() ValueType
+}
+
+type hashSum struct {
+	sum uint64
+	typ ValueType
+}
+
+func (v hashSum) Sum64() uint64        { return v.sum }
+func (v hashSum) ValueType() ValueType { return v.typ }
+
+// typeOf returns the type of v, or ValueTypeUnknown if v is not typed.
+func typeOf(v Value) ValueType {
+	if tv, ok := v.(TypedValue); ok {
+		return tv.ValueType()
+	}
+	return ValueTypeUnknown
+}
 
-func (v hashSum) Sum64() uint64 { return uint64(v) }
+// HashValue converts a hash, pre-computed by one of the functions of the hash
+// package, to a Value of type t. Use ValueTypeUnknown if the type of the
+// original value is not known.
+func HashValue(sum uint64, t ValueType) Value {
+	return hashSum{sum: sum, typ: t}
+}
+
+// TupleValue combines multiple values into a single Value, e.g. for counting
+// distinct (user_id, device_id) pairs. The order of the values is significant.
+// See hash.Tuple for details on the scheme. Tuples are untyped values.
+func TupleValue(vs ...Value) Value {
+	var arr [8]uint64
+	sums := arr[:0]
+	for _, v := range vs {
+		sums = append(sums, v.Sum64())
+	}
+	return hashSum{sum: hash.Tuple(sums...), typ: ValueTypeUnknown}
+}
 
 // StringValue converts a string to a Value.
 func StringValue(s string) Value {
-	return BinaryValue([]byte(s))
+	return hashSum{sum: hash.String(s), typ: ValueTypeBytes}
 }
 
 // BinaryValue converts a byte slice to a Value.
 func BinaryValue(p []byte) Value {
-	return hashSum(hash.Bytes(p))
+	return hashSum{sum: hash.Bytes(p), typ: ValueTypeBytes}
+}
+
+// Int32Value converts a number to a Value.
+func Int32Value(v int32) Value {
+	return hashSum{sum: hash.Int32(v), typ: ValueTypeInt32}
+}
+
+// Int64Value converts a number to a Value.
+func Int64Value(v int64) Value {
+	return hashSum{sum: hash.Int64(v), typ: ValueTypeInt64}
+}
+
+// Float32Value converts a number to a Value. All NaNs are treated as the same
+// value, regardless of their sign and payload, and so are -0.0 and +0.0.
+func Float32Value(v float32) Value {
+	return hashSum{sum: hash.Float32(v), typ: ValueTypeFloat}
+}
+
+// Float64Value converts a number to a Value. All NaNs are treated as the same
+// value, regardless of their sign and payload, and so are -0.0 and +0.0.
+func Float64Value(v float64) Value {
+	return hashSum{sum: hash.Float64(v), typ: ValueTypeDouble}
 }
 
 // Uint32Value converts a number to a Value.
 func Uint32Value(v uint32) Value {
-	return hashSum(hash.Uint32(v))
+	return hashSum{sum: hash.Uint32(v), typ: ValueTypeUint32}
 }
 
 // Uint64Value converts a number slice to a Value.
 func Uint64Value(v uint64) Value {
-	return hashSum(hash.Uint64(v))
+	return hashSum{sum: hash.Uint64(v), typ: ValueTypeUint64}
 }
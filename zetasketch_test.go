@@ -0,0 +1,186 @@
+package zetasketch_test
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/bsm/zetasketch"
+	"github.com/bsm/zetasketch/hash"
+)
+
+// newNormalHLL returns an aggregator in normal representation, where adding
+// values never allocates.
+func newNormalHLL() *zetasketch.HLL {
+	h := zetasketch.NewHLL(nil)
+	for i := range 100_000 {
+		h.Add(zetasketch.Uint64Value(uint64(i)))
+	}
+	return h
+}
+
+func TestHLL_typedAdders(t *testing.T) {
+	str := strings.Repeat("foobar", 12)
+
+	exp, got := zetasketch.NewHLL(nil), zetasketch.NewHLL(nil)
+	for i := range 100 {
+		exp.Add(zetasketch.Int64Value(int64(i)))
+		exp.Add(zetasketch.Uint64Value(uint64(i)))
+		got.AddInt64(int64(i))
+		got.AddUint64(uint64(i))
+	}
+	if got.Result() != exp.Result() || got.ValueType() != exp.ValueType() {
+		t.Errorf("got %d/%v, want %d/%v", got.Result(), got.ValueType(), exp.Result(), exp.ValueType())
+	}
+
+	cases := []struct {
+		name string
+		exp  zetasketch.Value
+		add  func(*zetasketch.HLL)
+	}{
+		{"String", zetasketch.StringValue(str), func(h *zetasketch.HLL) { h.AddString(str) }},
+		{"Bytes", zetasketch.BinaryValue([]byte(str)), func(h *zetasketch.HLL) { h.AddBytes([]byte(str)) }},
+		{"Int32", zetasketch.Int32Value(-7), func(h *zetasketch.HLL) { h.AddInt32(-7) }},
+		{"Uint32", zetasketch.Uint32Value(7), func(h *zetasketch.HLL) { h.AddUint32(7) }},
+		{"Float32", zetasketch.Float32Value(1.5), func(h *zetasketch.HLL) { h.AddFloat32(1.5) }},
+		{"Float64", zetasketch.Float64Value(1.5), func(h *zetasketch.HLL) { h.AddFloat64(1.5) }},
+	}
+	for _, tc := range cases {
+		exp, got := zetasketch.NewHLL(nil), zetasketch.NewHLL(nil)
+		exp.Add(tc.exp)
+		tc.add(got)
+
+		b1, _ := exp.MarshalBinary()
+		b2, _ := got.MarshalBinary()
+		if !bytes.Equal(b1, b2) {
+			t.Errorf("%s: expected the same state as Add", tc.name)
+		}
+	}
+}
+
+func TestHLL_allocs(t *testing.T) {
+	str := strings.Repeat("foobar", 12)
+	bin := []byte(str)
+	subject := newNormalHLL()
+
+	cases := map[string]func(int){
+		"AddString":  func(i int) { subject.AddString(str) },
+		"AddBytes":   func(i int) { subject.AddBytes(bin) },
+		"AddInt32":   func(i int) { subject.AddInt32(int32(i)) },
+		"AddInt64":   func(i int) { subject.AddInt64(int64(i)) },
+		"AddUint32":  func(i int) { subject.AddUint32(uint32(i)) },
+		"AddUint64":  func(i int) { subject.AddUint64(uint64(i)) },
+		"AddFloat32": func(i int) { subject.AddFloat32(float32(i)) },
+		"AddFloat64": func(i int) { subject.AddFloat64(float64(i)) },
+		"AddHash":    func(i int) { subject.AddHash(hash.Int64(int64(i))) },
+	}
+	for name, fn := range cases {
+		var i int
+		if n := testing.AllocsPerRun(100, func() { i++; fn(i) }); n != 0 {
+			t.Errorf("%s: expected no allocations, got %v", name, n)
+		}
+	}
+}
+
+func TestConcurrentHLL_allocs(t *testing.T) {
+	subject := zetasketch.NewConcurrentHLL(&zetasketch.HLLConfig{Precision: 10})
+	for i := range runtime.GOMAXPROCS(0) * 10_000 {
+		subject.AddInt64(int64(i))
+	}
+
+	var i int
+	if n := testing.AllocsPerRun(100, func() { i++; subject.AddInt64(int64(i)) }); n != 0 {
+		t.Errorf("AddInt64: expected no allocations, got %v", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { i++; subject.AddHash(hash.Int64(int64(i))) }); n != 0 {
+		t.Errorf("AddHash: expected no allocations, got %v", n)
+	}
+}
+
+func TestHLLOf_allocs(t *testing.T) {
+	subject := zetasketch.NewInt64HLL(nil)
+	for i := range 100_000 {
+		subject.Add(int64(i))
+	}
+
+	var i int
+	if n := testing.AllocsPerRun(100, func() { i++; subject.Add(int64(i)) }); n != 0 {
+		t.Errorf("expected no allocations, got %v", n)
+	}
+}
+
+var benchSum uint64
+
+func BenchmarkStringValue(b *testing.B) {
+	str := strings.Repeat("foobar", 12)
+	b.ReportAllocs()
+	for b.Loop() {
+		benchSum = zetasketch.StringValue(str).Sum64()
+	}
+}
+
+func BenchmarkBinaryValue(b *testing.B) {
+	bin := []byte(strings.Repeat("foobar", 12))
+	b.ReportAllocs()
+	for b.Loop() {
+		benchSum = zetasketch.BinaryValue(bin).Sum64()
+	}
+}
+
+func BenchmarkInt32Value(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		benchSum = zetasketch.Int32Value(int32(i)).Sum64()
+	}
+}
+
+func BenchmarkInt64Value(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		benchSum = zetasketch.Int64Value(int64(i)).Sum64()
+	}
+}
+
+func BenchmarkUint32Value(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		benchSum = zetasketch.Uint32Value(uint32(i)).Sum64()
+	}
+}
+
+func BenchmarkUint64Value(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		benchSum = zetasketch.Uint64Value(uint64(i)).Sum64()
+	}
+}
+
+func BenchmarkFloat32Value(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		benchSum = zetasketch.Float32Value(float32(i)).Sum64()
+	}
+}
+
+func BenchmarkFloat64Value(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		benchSum = zetasketch.Float64Value(float64(i)).Sum64()
+	}
+}
+
+func BenchmarkHashValue(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		benchSum = zetasketch.HashValue(uint64(i), zetasketch.ValueTypeUnknown).Sum64()
+	}
+}
+
+func BenchmarkHLL_AddHash(b *testing.B) {
+	subject := newNormalHLL()
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		subject.AddHash(hash.Int64(int64(i)))
+	}
+}